@@ -2,15 +2,15 @@ package bouncer
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"reflect"
 	"strings"
-
-	"github.com/gorilla/context"
 )
 
 const (
@@ -19,29 +19,51 @@ const (
 )
 
 type BouncerHandler struct {
-	iface interface{}
-	f     http.Handler
+	bouncer *Bouncer
+	options options
+	iface   interface{}
+	f       http.Handler
 }
 
 type BouncerPatchHandler struct {
+	bouncer       *Bouncer
+	options       options
 	iface         interface{}
 	maxBodyLength int64
 	f             http.Handler
 }
 
-func NewBouncerHandler(obj interface{}, f http.Handler) http.Handler {
+func NewBouncerHandler(obj interface{}, f http.Handler, opts ...Option) http.Handler {
+	return DefaultBouncer.Handler(obj, f, opts...)
+}
+
+func NewBouncerPatchHandler(obj interface{}, maxBodyLength int64, f http.Handler, opts ...Option) http.Handler {
+	return DefaultBouncer.PatchHandler(obj, maxBodyLength, f, opts...)
+}
+
+// Handler is like NewBouncerHandler, but validates with the rules registered on b.
+func (b *Bouncer) Handler(obj interface{}, f http.Handler, opts ...Option) http.Handler {
+	o := b.handlerOptions(opts)
+	b.mustCheck(obj, true, o)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		h := BouncerHandler{
-			f:     f,
-			iface: obj,
+			bouncer: b,
+			options: o,
+			f:       f,
+			iface:   obj,
 		}
 		h.ServeHTTP(w, r)
 	})
 }
 
-func NewBouncerPatchHandler(obj interface{}, maxBodyLength int64, f http.Handler) http.Handler {
+// PatchHandler is like NewBouncerPatchHandler, but validates with the rules registered on b.
+func (b *Bouncer) PatchHandler(obj interface{}, maxBodyLength int64, f http.Handler, opts ...Option) http.Handler {
+	o := b.handlerOptions(opts)
+	b.mustCheck(obj, true, o)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		h := BouncerPatchHandler{
+			bouncer:       b,
+			options:       o,
 			f:             f,
 			maxBodyLength: maxBodyLength,
 			iface:         obj,
@@ -51,7 +73,10 @@ func NewBouncerPatchHandler(obj interface{}, maxBodyLength int64, f http.Handler
 }
 
 func (h BouncerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	errs := Validate(h.iface, r)
+	body, errs := h.bouncer.validateRequest(h.iface, r, h.options)
+	if body != nil {
+		r = r.WithContext(withDecodedBody(r.Context(), body))
+	}
 
 	if len(errs) > 0 {
 		ErrorHandler(errs, w)
@@ -63,47 +88,122 @@ func (h BouncerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h BouncerPatchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r, _, errs := h.validate(w, r)
+	if len(errs) > 0 {
+		ErrorHandler(errs, w)
+		return
+	}
+
+	h.f.ServeHTTP(w, r)
+
+}
+
+// validate reads and validates the patch body, returning the decoded model and
+// the request with the sanitized patch json, containing only the keys that were
+// sent, in its context. A JSON Patch body is validated as a list of operations
+// instead, see PatchOperations.
+func (h BouncerPatchHandler) validate(w http.ResponseWriter, r *http.Request) (*http.Request, interface{}, Errors) {
 	var errors Errors
 
 	mr := http.MaxBytesReader(w, r.Body, h.maxBodyLength)
 	defer mr.Close() //also closes r.Body
 	jsonData, err := ioutil.ReadAll(mr)
 	if err != nil {
-		errors.Add([]string{}, DeserializationError, err.Error())
-		ErrorHandler(errors, w)
-		return
+		addBodyError(&errors, err)
+		return r, nil, errors
+	}
+
+	mediaType, _ := requestMediaType(r)
+	if mediaType == jsonPatchContentType && accepts(h.options.mediaTypes, mediaType) {
+		if msg := checkJsonLimits(jsonData, h.options); msg != "" {
+			errors.Add([]string{}, PayloadTooLargeError, msg)
+			return r, nil, errors
+		}
+		operations, errs := h.bouncer.validateJsonPatch(r.Context(), h.iface, h.options.profileFor(r.Method), jsonData)
+		model := reflect.New(modelType(h.iface)).Interface()
+		errs = h.bouncer.bindHeaders(r.Context(), errs, model, r.Header)
+		if len(errs) > 0 {
+			return r, nil, errs
+		}
+		patchJson, _ := json.Marshal(operations)
+		ctx := withPatchOperations(withPatchBody(r.Context(), patchJson), operations)
+		return r.WithContext(ctx), model, nil
+	}
+
+	decode, errs := h.bouncer.decoderFor(r, h.options.mediaTypes)
+	if len(errs) > 0 {
+		return r, nil, errs
+	}
+
+	if !isJsonMediaType(mediaType) {
+		r.Body = ioutil.NopCloser(bytes.NewReader(jsonData))
+		mergeObject, fields, errs := decode(h.iface, r, h.options)
+		errs = h.bouncer.bindHeaders(r.Context(), errs, mergeObject, r.Header)
+		errs = h.bouncer.validateModel(r.Context(), errs, mergeObject, fields, r.Method, h.options)
+		if len(errs) > 0 {
+			return r, nil, errs
+		}
+
+		// there is no original json to merge into, so only keep the keys that were sent
+		mergeJson, err := json.Marshal(mergeObject)
+		if err == nil {
+			mergeJson, err = fields.filterJson(mergeJson)
+		}
+		if err != nil {
+			errors.Add([]string{}, DeserializationError, err.Error())
+			return r, nil, errors
+		}
+		return r.WithContext(withPatchBody(r.Context(), mergeJson)), mergeObject, nil
 	}
 
 	// validate json, potentially modify it
-	mergeObject, errs := ValidateJson(h.iface, jsonData, r.Method)
+	mergeObject, fields, errs := decodeJson(h.iface, bytes.NewReader(jsonData), h.options)
+	errs = h.bouncer.bindHeaders(r.Context(), errs, mergeObject, r.Header)
+	errs = h.bouncer.validateModel(r.Context(), errs, mergeObject, fields, r.Method, h.options)
 	if len(errs) > 0 {
-		ErrorHandler(errs, w)
-		return
+		return r, nil, errs
 	}
 
 	// marshall the json object back to a string
 	mergeJson, err := json.Marshal(mergeObject)
 	if err != nil {
 		errors.Add([]string{}, DeserializationError, err.Error())
-		ErrorHandler(errors, w)
-		return
+		return r, nil, errors
 	}
 
 	// ensure the final object only contains keys that it started with
-	finalJson, err := CreateEncodedInterfaceFromOriginal(jsonData, mergeJson)
+	m := merger{keepNulls: h.options.nullablePatch, model: modelType(h.iface)}
+	finalJson, err := m.createEncodedInterfaceFromOriginal(jsonData, mergeJson)
 	if err != nil {
 		errors.Add([]string{}, DeserializationError, err.Error())
-		ErrorHandler(errors, w)
-		return
+		return r, nil, errors
 	}
 
-	context.Set(r, "requestBody", finalJson)
+	return r.WithContext(withPatchBody(r.Context(), finalJson)), mergeObject, nil
+}
 
-	h.f.ServeHTTP(w, r)
+func CreateEncodedInterfaceFromOriginal(originalJson []byte, latestJson []byte) ([]byte, error) {
+	return merger{}.createEncodedInterfaceFromOriginal(originalJson, latestJson)
+}
+
+// MergeInterface copies the values of src into dest, for the keys dest already has.
+// Lists of the same length are merged item by item.
+func MergeInterface(dest interface{}, src interface{}) (interface{}, error) {
+	return merger{}.merge(dest, src)
+}
+
+// merger merges the sanitized patch data back into the original patch input.
+type merger struct {
+	// keepNulls keeps explicit nulls from the original input, instead of
+	// replacing them with the zero values they were decoded into
+	keepNulls bool
 
+	// model is the type the input was decoded into, used to find the
+	// merge:"key=..." tags of lists. It may be nil.
+	model reflect.Type
 }
 
-func CreateEncodedInterfaceFromOriginal(originalJson []byte, latestJson []byte) ([]byte, error) {
+func (m merger) createEncodedInterfaceFromOriginal(originalJson []byte, latestJson []byte) ([]byte, error) {
 	var originalInterface interface{}
 	var latestInterface interface{}
 
@@ -120,7 +220,7 @@ func CreateEncodedInterfaceFromOriginal(originalJson []byte, latestJson []byte)
 	}
 
 	// Merge the modified input into the originalJson, ignoring fields that didn't exist in the originalJson (these were added when unmarshalled)
-	finalInterface, err := MergeInterface(originalInterface, latestInterface)
+	finalInterface, err := m.merge(originalInterface, latestInterface)
 	if err != nil {
 		return nil, err
 	}
@@ -133,8 +233,15 @@ func CreateEncodedInterfaceFromOriginal(originalJson []byte, latestJson []byte)
 	return finalJson, nil
 }
 
-func MergeInterface(dest interface{}, src interface{}) (interface{}, error) {
+func (m merger) merge(dest interface{}, src interface{}) (interface{}, error) {
+	return m.mergeValue(dest, src, m.model, "")
+}
+
+// mergeValue merges src into dest, where typ is the type dest was decoded into
+// (or nil if it isn't known) and key is the merge key of a list, if any.
+func (m merger) mergeValue(dest interface{}, src interface{}, typ reflect.Type, key string) (interface{}, error) {
 	var err error
+	typ = mergeType(typ)
 
 	switch destMap := dest.(type) {
 	case map[string]interface{}:
@@ -147,7 +254,8 @@ func MergeInterface(dest interface{}, src interface{}) (interface{}, error) {
 				}
 
 				// potentially update the value of destMap for the current key
-				destMap[key], err = MergeInterface(destMap[key], srcMap[key])
+				fieldType, mergeKey := mergeField(typ, key)
+				destMap[key], err = m.mergeValue(destMap[key], srcMap[key], fieldType, mergeKey)
 				if err != nil {
 					return nil, err
 				}
@@ -158,15 +266,111 @@ func MergeInterface(dest interface{}, src interface{}) (interface{}, error) {
 		}
 		return nil, errors.New("fatal issue merging sanitized patch data")
 	case []interface{}:
-		// no naive way to know which items in an unordered list are associated
-		// so arrays will have to be passed through unmodified
-		return dest, nil
+		destItems := destMap
+		srcList, ok := src.([]interface{})
+		if !ok {
+			return dest, nil
+		}
+		var elemType reflect.Type
+		if typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+			elemType = typ.Elem()
+		}
+
+		// lists of objects with a merge key are matched up by the value of that key
+		if key != "" {
+			for i, destItem := range destItems {
+				srcItem, ok := findByKey(srcList, key, destItem)
+				if !ok && len(destItems) == len(srcList) {
+					// a key changed by sanitizing, e.g. trimmed, matches nothing,
+					// but the sanitized list holds the same items in the same order
+					srcItem, ok = srcList[i], true
+				}
+				if ok {
+					if destItems[i], err = m.mergeValue(destItem, srcItem, elemType, ""); err != nil {
+						return nil, err
+					}
+				}
+			}
+			return destItems, nil
+		}
+
+		// otherwise, there is no naive way to know which items are associated unless
+		// the lists have the same length, so they have to be passed through unmodified
+		if len(destItems) != len(srcList) {
+			return dest, nil
+		}
+		for i := range destItems {
+			if destItems[i], err = m.mergeValue(destItems[i], srcList[i], elemType, ""); err != nil {
+				return nil, err
+			}
+		}
+		return destItems, nil
+	case nil:
+		if m.keepNulls {
+			return nil, nil
+		}
+		return src, nil
 	default:
 		// if we get here it shouldn't be from a top level call, so must be recusrive from a range over the original patch fields
 		return src, nil
 	}
 }
 
+// findByKey finds the object in list with the same value for key as item.
+func findByKey(list []interface{}, key string, item interface{}) (interface{}, bool) {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	value, ok := itemMap[key]
+	if !ok {
+		return nil, false
+	}
+
+	for _, candidate := range list {
+		if candidateMap, ok := candidate.(map[string]interface{}); ok && reflect.DeepEqual(candidateMap[key], value) {
+			return candidate, true
+		}
+	}
+	return nil, false
+}
+
+// mergeType strips pointers and Nullable from a type, leaving the one the json is decoded into.
+func mergeType(typ reflect.Type) reflect.Type {
+	for typ != nil {
+		switch {
+		case typ.Kind() == reflect.Ptr:
+			typ = typ.Elem()
+		case typ.Kind() == reflect.Struct && typ.Implements(nullableFieldType):
+			typ = typ.Field(0).Type
+		default:
+			return typ
+		}
+	}
+	return nil
+}
+
+// mergeField returns the type of the value for key in an object decoded into typ,
+// along with the key from its merge:"key=..." tag.
+func mergeField(typ reflect.Type, key string) (reflect.Type, string) {
+	if typ == nil {
+		return nil, ""
+	}
+
+	switch typ.Kind() {
+	case reflect.Map:
+		return typ.Elem(), ""
+	case reflect.Struct:
+		if field, ok := fieldByJsonName(typ, key); ok {
+			if tag := field.Tag.Get("merge"); strings.HasPrefix(tag, "key=") {
+				return field.Type, tag[len("key="):]
+			}
+			return field.Type, ""
+		}
+	}
+	return nil, ""
+}
+
 // ErrorHandler simply counts the number of errors in the
 // context and, if more than 0, writes a response with an
 // error code and a JSON payload describing the errors.
@@ -179,7 +383,9 @@ func MergeInterface(dest interface{}, src interface{}) (interface{}, error) {
 func ErrorHandler(errs Errors, resp http.ResponseWriter) {
 	if len(errs) > 0 {
 		resp.Header().Set("Content-Type", jsonContentType)
-		if errs.Has(DeserializationError) {
+		if errs.Has(PayloadTooLargeError) {
+			resp.WriteHeader(http.StatusRequestEntityTooLarge)
+		} else if errs.Has(DeserializationError) {
 			resp.WriteHeader(http.StatusBadRequest)
 		} else if errs.Has(ContentTypeError) {
 			resp.WriteHeader(http.StatusUnsupportedMediaType)
@@ -192,52 +398,100 @@ func ErrorHandler(errs Errors, resp http.ResponseWriter) {
 	}
 }
 
-func Validate(obj interface{}, req *http.Request) Errors {
+// Validate decodes and validates the request body into a new instance of obj.
+// The returned request carries the decoded body in its context, see DecodedBody.
+func Validate(obj interface{}, req *http.Request, opts ...Option) (*http.Request, Errors) {
+	return DefaultBouncer.Validate(obj, req, opts...)
+}
+
+// Validate is like the package level Validate, but uses the rules registered on b.
+func (b *Bouncer) Validate(obj interface{}, req *http.Request, opts ...Option) (*http.Request, Errors) {
+	body, errors := b.validateRequest(obj, req, b.handlerOptions(opts))
+	if body != nil {
+		req = req.WithContext(withDecodedBody(req.Context(), body))
+	}
+	return req, errors
+}
+
+// Json is like Validate, but always treats the body as json.
+func Json(jsonStruct interface{}, req *http.Request) (*http.Request, Errors) {
+	return DefaultBouncer.Json(jsonStruct, req)
+}
+
+// Json is like the package level Json, but uses the rules registered on b.
+func (b *Bouncer) Json(jsonStruct interface{}, req *http.Request) (*http.Request, Errors) {
+	body, errors := b.validateJsonFromReader(req.Context(), jsonStruct, limitBody(req, b.options), req.Method, b.options)
+	req = req.WithContext(withDecodedBody(req.Context(), body))
+	return req, errors
+
+}
+
+// validateRequest decodes and validates the request body, returning a pointer
+// to the decoded model. The returned body is nil if the request had nothing to validate.
+// Bodies are dispatched to a decoder by their media type.
+func (b *Bouncer) validateRequest(obj interface{}, req *http.Request, o options) (interface{}, Errors) {
 	contentType := req.Header.Get("Content-Type")
 	if req.Method == "POST" || req.Method == "PUT" || req.Method == "PATCH" || contentType != "" {
 
-		if strings.Contains(contentType, "json") {
-			return Json(obj, req)
+		decode, errs := b.decoderFor(req, o.mediaTypes)
+		if len(errs) > 0 {
+			return nil, errs
 		}
-		return Json(obj, req)
+		req.Body = limitBody(req, o)
+		body, fields, errors := decode(obj, req, o)
+		errors = b.bindHeaders(req.Context(), errors, body, req.Header)
+		return body, b.validateModel(req.Context(), errors, body, fields, req.Method, o)
 	}
-	return nil
+	return nil, nil
 }
 
-func Json(jsonStruct interface{}, req *http.Request) Errors {
-	body, errors := validateJsonFromReader(jsonStruct, req.Body, req.Method)
-	context.Set(req, "decodedBody", body)
-	return errors
-
+func ValidateJson(jsonStruct interface{}, jsonData []byte, method string) (interface{}, Errors) {
+	return DefaultBouncer.ValidateJson(jsonStruct, jsonData, method)
 }
 
-func ValidateJson(jsonStruct interface{}, jsonData []byte, method string) (interface{}, Errors) {
-	return validateJsonFromReader(jsonStruct, bytes.NewReader(jsonData), method)
+// ValidateJson is like the package level ValidateJson, but uses the rules registered on b.
+func (b *Bouncer) ValidateJson(jsonStruct interface{}, jsonData []byte, method string) (interface{}, Errors) {
+	return b.validateJsonFromReader(context.Background(), jsonStruct, bytes.NewReader(jsonData), method, b.options)
 }
 
-func validateJsonFromReader(jsonStruct interface{}, reader io.Reader, method string) (interface{}, Errors) {
-	var errors Errors
-	ensureNotPointer(jsonStruct)
-	obj := reflect.New(reflect.TypeOf(jsonStruct))
+func (b *Bouncer) validateJsonFromReader(ctx context.Context, jsonStruct interface{}, reader io.Reader, method string, o options) (interface{}, Errors) {
+	obj, fields, errors := decodeJson(jsonStruct, reader, o)
+	return obj, b.validateModel(ctx, errors, obj, fields, method, o)
 
-	if reader != nil {
-		err := json.NewDecoder(reader).Decode(obj.Interface())
-		if err != nil && err != io.EOF {
-			errors.Add([]string{}, DeserializationError, err.Error())
-		}
-	}
+}
 
-	if method == "PATCH" {
-		errors = validatePatchStruct(errors, obj.Interface())
-	} else if method == "POST" || method == "PUT" {
-		errors = validateCreateStruct(errors, obj.Interface())
+// limitBody caps the request body at the size limit in o, if there is one.
+func limitBody(req *http.Request, o options) io.ReadCloser {
+	if req.Body == nil || o.maxBodySize <= 0 {
+		return req.Body
 	}
+	return http.MaxBytesReader(nil, req.Body, o.maxBodySize)
+}
 
-	return obj.Interface(), errors
+// validateModel runs the tag based validation of the profile for method in o
+// on a decoded model, followed by the model's Validator if it has one. Errors
+// are named by their path from the root of the body. Models that are lists or
+// maps have their elements validated, with errors named by their index or
+// key, e.g. "[3].sku".
+func (b *Bouncer) validateModel(ctx context.Context, errors Errors, obj interface{}, fields *presence, method string, o options) Errors {
+	if profile := o.profileFor(method); profile != "" {
+		if value := reflect.ValueOf(obj); value.Elem().Kind() == reflect.Struct {
+			errors = b.validateStruct(ctx, errors, profile, obj, fields, rootPath(o))
+		} else {
+			errors = b.validateElements(ctx, errors, profile, value, fields, rootPath(o))
+		}
+	}
 
+	return runValidator(errors, obj, method)
 }
 
-func validateCreateStruct(errors Errors, obj interface{}) Errors {
+// validateStruct checks the rules in the struct tags of obj, using the
+// required and immutable rules from the tag named by tagKey: a profile, such
+// as "create" or "patch", or "query".
+// fields records the keys that were sent for obj; a nil presence means
+// this isn't known, and fields are assumed to be present if they are not zero.
+// Errors are named by their path from path, the path to obj.
+func (b *Bouncer) validateStruct(ctx context.Context, errors Errors, tagKey string, obj interface{}, fields *presence, path fieldPath) Errors {
 	typ := reflect.TypeOf(obj)
 	val := reflect.ValueOf(obj)
 
@@ -246,136 +500,179 @@ func validateCreateStruct(errors Errors, obj interface{}) Errors {
 		val = val.Elem()
 	}
 
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
+	// fields of embedded structs are validated as fields of obj, as they are
+	// decoded by encoding/json
+	plan := b.plan(typ)
+	for i := range plan.fields {
+		f := &plan.fields[i]
+
+		// Skip ignored fields, and headers which are checked by bindHeaders
+		if f.skipped || f.header != "" {
+			continue
+		}
+
+		// Fields promoted through a nil embedded pointer weren't sent, but
+		// may still be required
+		fieldVal, ok := f.value(val, false)
+		if !ok {
+			present, sent := fields.structField(f.jsonField, reflect.Value{})
+			if f.mode(tagKey).required && (!sent || present.isNull()) {
+				errors.Add([]string{path.key(f.errorName).String()}, RequiredError, "Required")
+			}
+			continue
+		}
 
-		// Skip ignored and unexported fields in the struct
-		if field.Tag.Get("form") == "-" || !val.Field(i).CanInterface() {
+		// Skip unexported fields
+		if !fieldVal.CanInterface() {
 			continue
 		}
 
-		fieldValue := val.Field(i).Interface()
-		zero := reflect.Zero(field.Type).Interface()
+		// Nullable fields are validated by the value they hold
+		fieldActualValue := f.actual(fieldVal)
+		present, sent := fields.structField(f.jsonField, fieldVal)
+		name := path.key(f.errorName)
 
 		// If the field Value is a string, then trim the leading spaces
-		if field.Tag.Get("notrim") != "true" {
-			fieldActualValue := val.Field(i)
-			if fieldActualValue.IsValid() {
-				if fieldActualValue.CanSet() {
-					if fieldActualValue.Kind() == reflect.String {
-						fieldActualValue.SetString(strings.TrimSpace(fieldValue.(string)))
-					}
-				}
-			}
+		if !f.notrim && fieldActualValue.Kind() == reflect.String && fieldActualValue.CanSet() {
+			fieldActualValue.SetString(strings.TrimSpace(fieldActualValue.String()))
 		}
 
 		// Validate nested and embedded structs (if pointer, only do so if not nil)
-		if field.Type.Kind() == reflect.Struct ||
-			(field.Type.Kind() == reflect.Ptr && !reflect.DeepEqual(zero, fieldValue) &&
-				field.Type.Elem().Kind() == reflect.Struct) {
-			errors = validateCreateStruct(errors, fieldValue)
-		}
-
-		if field.Tag.Get("create") == "-" {
-			//this is immutable - make sure it's zero
-			if !reflect.DeepEqual(zero, fieldValue) {
-				name := field.Name
-				if j := field.Tag.Get("json"); j != "" {
-					name = j
-				} else if f := field.Tag.Get("form"); f != "" {
-					name = f
+		if present.isValid() && f.nested {
+			if fieldActualValue.Kind() == reflect.Ptr {
+				if !fieldActualValue.IsNil() {
+					errors = b.validateStruct(ctx, errors, tagKey, fieldActualValue.Interface(), present, name)
 				}
-				errors.Add([]string{name}, ImmutableError, "Immutable")
+			} else if fieldActualValue.CanAddr() {
+				// pass nested structs by address, so their strings can be trimmed
+				errors = b.validateStruct(ctx, errors, tagKey, fieldActualValue.Addr().Interface(), present, name)
+			} else {
+				errors = b.validateStruct(ctx, errors, tagKey, fieldActualValue.Interface(), present, name)
 			}
 		}
 
-		if strings.Index(field.Tag.Get("create"), "required") > -1 {
-			if reflect.DeepEqual(zero, fieldValue) {
-				name := field.Name
-				if j := field.Tag.Get("json"); j != "" {
-					name = j
-				} else if f := field.Tag.Get("form"); f != "" {
-					name = f
-				}
-				errors.Add([]string{name}, RequiredError, "Required")
+		// Validate the structs in lists and maps
+		if present.isValid() && f.elements {
+			errors = b.validateElements(ctx, errors, tagKey, fieldActualValue, present, name)
+		}
+
+		mode := f.mode(tagKey)
+		if mode.immutable {
+			//this is immutable - make sure it wasn't sent with a value other than zero
+			if sent && !isZero(fieldVal) {
+				errors.Add([]string{name.String()}, ImmutableError, "Immutable")
+			}
+		}
+
+		if mode.required {
+			if !sent || present.isNull() {
+				errors.Add([]string{name.String()}, RequiredError, "Required")
 			}
 		}
+
+		if mode.nonnull {
+			if present.isNull() {
+				errors.Add([]string{name.String()}, NullError, "Must not be null")
+			}
+		}
+
+		if sent && !present.isNull() && present.isValid() {
+			errors = b.validateRules(ctx, errors, name, f, fieldActualValue, val, present)
+		}
 	}
 	return errors
 
 }
 
-func validatePatchStruct(errors Errors, obj interface{}) Errors {
-	typ := reflect.TypeOf(obj)
-	val := reflect.ValueOf(obj)
-
-	if typ.Kind() == reflect.Ptr {
-		typ = typ.Elem()
-		val = val.Elem()
+// validateElements validates the structs held by a slice, array or map (or a
+// pointer to one), including those in nested lists and maps, naming errors
+// by the index or key of the element.
+func (b *Bouncer) validateElements(ctx context.Context, errors Errors, tagKey string, value reflect.Value, fields *presence, path fieldPath) Errors {
+	value = indirect(value)
+	if kind := value.Kind(); (kind != reflect.Slice && kind != reflect.Array && kind != reflect.Map) ||
+		!holdsStructs(value.Type().Elem()) {
+		return errors
 	}
 
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-
-		// Skip ignored and unexported fields in the struct
-		if field.Tag.Get("form") == "-" || !val.Field(i).CanInterface() {
-			continue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			errors = b.validateElement(ctx, errors, tagKey, value.Index(i), fields.item(i), path.index(i))
 		}
-
-		fieldValue := val.Field(i).Interface()
-		zero := reflect.Zero(field.Type).Interface()
-
-		// If the field Value is a string, then trim the leading spaces
-		if field.Tag.Get("notrim") != "true" {
-			fieldActualValue := val.Field(i)
-			if fieldActualValue.IsValid() {
-				if fieldActualValue.CanSet() {
-					if fieldActualValue.Kind() == reflect.String {
-						fieldActualValue.SetString(strings.TrimSpace(fieldValue.(string)))
-					}
-				}
-			}
+	case reflect.Map:
+		for _, key := range sortedMapKeys(value) {
+			name := fmt.Sprint(key.Interface())
+
+			// map values can't be changed in place, so they are trimmed on a copy
+			elem := reflect.New(value.Type().Elem()).Elem()
+			elem.Set(value.MapIndex(key))
+			errors = b.validateElement(ctx, errors, tagKey, elem, fields.child(name), path.key(name))
+			value.SetMapIndex(key, elem)
 		}
+	}
+	return errors
+}
 
-		// Validate nested and embedded structs (if pointer, only do so if not nil)
-		if field.Type.Kind() == reflect.Struct ||
-			(field.Type.Kind() == reflect.Ptr && !reflect.DeepEqual(zero, fieldValue) &&
-				field.Type.Elem().Kind() == reflect.Struct) {
-			errors = validatePatchStruct(errors, fieldValue)
-		}
-
-		if field.Tag.Get("patch") == "-" {
-			//this is immutable - make sure it's zero
-			if !reflect.DeepEqual(zero, fieldValue) {
-				name := field.Name
-				if j := field.Tag.Get("json"); j != "" {
-					name = j
-				} else if f := field.Tag.Get("form"); f != "" {
-					name = f
-				}
-				errors.Add([]string{name}, ImmutableError, "Immutable")
-			}
-		}
+// holdsStructs reports whether values of typ are, or contain, structs to validate.
+func holdsStructs(typ reflect.Type) bool {
+	typ = indirectType(typ)
+	switch typ.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return holdsStructs(typ.Elem())
+	}
+	return false
+}
 
-		if strings.Index(field.Tag.Get("patch"), "required") > -1 {
-			if reflect.DeepEqual(zero, fieldValue) {
-				name := field.Name
-				if j := field.Tag.Get("json"); j != "" {
-					name = j
-				} else if f := field.Tag.Get("form"); f != "" {
-					name = f
-				}
-				errors.Add([]string{name}, RequiredError, "Required")
-			}
+// validateElement validates a single element of a list or map.
+func (b *Bouncer) validateElement(ctx context.Context, errors Errors, tagKey string, elem reflect.Value, fields *presence, path fieldPath) Errors {
+	if !fields.isValid() || fields.isNull() {
+		return errors
+	}
+	elem = unwrapNullable(elem)
+	if elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			return errors
 		}
+		elem = elem.Elem()
+	}
+
+	switch elem.Kind() {
+	case reflect.Struct:
+		return b.validateStruct(ctx, errors, tagKey, elem.Addr().Interface(), fields, path)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return b.validateElements(ctx, errors, tagKey, elem, fields, path)
 	}
 	return errors
+}
 
+// fieldName returns the name a field is known by in requests and errors.
+func fieldName(field reflect.StructField) string {
+	if name, _ := headerName(field); name != "" {
+		return name
+	}
+	if field.Tag.Get("path") != "" || field.Tag.Get("query") != "" {
+		name, _ := queryName(field)
+		return name
+	}
+	if j := strings.Split(field.Tag.Get("json"), ",")[0]; j != "" {
+		return j
+	} else if f := field.Tag.Get("form"); f != "" {
+		return f
+	}
+	return field.Name
 }
 
-// Don't pass in pointers to bouncer
-func ensureNotPointer(obj interface{}) {
-	if reflect.TypeOf(obj).Kind() == reflect.Ptr {
-		panic("Pointers are not accepted as binding models")
+// jsonName returns the key encoding/json decodes a field from.
+func jsonName(field reflect.StructField) string {
+	if j := strings.Split(field.Tag.Get("json"), ",")[0]; j != "" {
+		return j
 	}
+	return field.Name
+}
+
+// modelType is the type of the model obj, which may be given as a pointer.
+func modelType(obj interface{}) reflect.Type {
+	return indirectType(reflect.TypeOf(obj))
 }
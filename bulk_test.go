@@ -0,0 +1,103 @@
+package bouncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newJsonRequest(method string, payload string) *http.Request {
+	req := httptest.NewRequest(method, testRoute, strings.NewReader(payload))
+	req.Header.Set("Content-Type", jsonContentType)
+	return req
+}
+
+func TestPointerModels(t *testing.T) {
+	var body interface{}
+	var fromContext *Foo
+	handler := NewBouncerHandler(&Foo{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = DecodedBody(r.Context())
+		fromContext, _ = Body[*Foo](r.Context())
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newJsonRequest("POST", `{"title":" Foo "}`))
+	if foo, ok := body.(*Foo); recorder.Code != http.StatusOK || !ok || foo.Title != "Foo" {
+		t.Fatalf("Expected a pointer model to be decoded like its struct, but got %d with '%+v'", recorder.Code, body)
+	}
+	if fromContext == nil || fromContext.Title != "Foo" {
+		t.Errorf("Expected Body to return the pointer to the decoded body, but got '%+v'", fromContext)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newJsonRequest("POST", `{}`))
+	if recorder.Code != StatusUnprocessableEntity {
+		t.Errorf("Expected a pointer model to be validated, but got %d", recorder.Code)
+	}
+
+	var typed *Foo
+	NewHandler(func(w http.ResponseWriter, r *http.Request, foo *Foo) {
+		typed = foo
+	}).ServeHTTP(httptest.NewRecorder(), newJsonRequest("POST", `{"title":"Foo"}`))
+	if typed == nil || typed.Title != "Foo" {
+		t.Errorf("Expected a typed handler of a pointer to get the decoded body, but got '%+v'", typed)
+	}
+}
+
+func TestListModels(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		opts        []Option
+		method      string
+		payload     string
+		paths       []string
+	}{
+		{"Valid", nil, "POST", `[{"sku":" A001 "},{"sku":"B002"}]`, nil},
+		{"Required in an element", nil, "POST", `[{"sku":"A001"},{"note":"x"}]`, []string{"[1].sku"}},
+		{"Rule in an element", nil, "PUT", `[{"sku":"A1"}]`, []string{"[0].sku"}},
+		{"Type error in an element", nil, "POST", `[{"sku":"A001"},{"sku":1}]`, []string{"[1].sku"}},
+		{"Unknown field in an element", []Option{Strict()}, "POST", `[{"sku":"A001","size":1}]`, []string{"[0].size"}},
+		{"Pointer notation", []Option{ErrorPaths(PointerNotation)}, "POST", `[{"sku":"A001"},{"note":"x"}]`, []string{"/1/sku"}},
+		{"Patch", nil, "PATCH", `[{"note":"x"},{"sku":null}]`, []string{"[1].sku"}},
+		{"Not a list", nil, "POST", `{"sku":"A001"}`, []string{""}},
+	} {
+		var lines []InvoiceLine
+		recorder := httptest.NewRecorder()
+		HandlerFor(New(testCase.opts...), func(w http.ResponseWriter, r *http.Request, body []InvoiceLine) {
+			lines = body
+		}).ServeHTTP(recorder, newJsonRequest(testCase.method, testCase.payload))
+
+		if len(testCase.paths) == 0 {
+			if recorder.Code != http.StatusOK || len(lines) != 2 || lines[0].Sku != "A001" {
+				t.Errorf("'%s' should have been valid, but got %d with '%s'", testCase.description, recorder.Code, recorder.Body.String())
+			}
+			continue
+		}
+		for _, path := range testCase.paths {
+			if !strings.Contains(recorder.Body.String(), `"fieldNames":["`+path+`"]`) {
+				t.Errorf("'%s' should have returned an error on '%s', but got %d with '%s'", testCase.description, path, recorder.Code, recorder.Body.String())
+			}
+		}
+	}
+}
+
+func TestListModelsRejectForms(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewBouncerHandler([]InvoiceLine{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).
+		ServeHTTP(recorder, newFormRequest("POST", url.Values{"sku": {"A001"}}))
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected a form for a list model to be rejected with a 415, but got %d", recorder.Code)
+	}
+}
+
+func TestQueryModelsMustBeStructs(t *testing.T) {
+	defer func() {
+		if _, ok := recover().(*ModelError); !ok {
+			t.Errorf("Expected a query handler for a list to panic with a ModelError")
+		}
+	}()
+
+	NewBouncerQueryHandler([]InvoiceLine{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+}
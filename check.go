@@ -0,0 +1,280 @@
+package bouncer
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ModelError lists the mistakes Check found in a model.
+type ModelError struct {
+	// Model is the type that was checked
+	Model reflect.Type
+
+	// Problems describes each mistake, starting with the go name of the
+	// field it is on, e.g. "Lines.Sku: unknown validation rule \"skuu\""
+	Problems []string
+}
+
+func (e *ModelError) Error() string {
+	return fmt.Sprintf("bouncer: invalid model %v: %s", e.Model, strings.Join(e.Problems, "; "))
+}
+
+// Check looks for mistakes in a model, see Bouncer.Check.
+func Check(obj interface{}) error {
+	return DefaultBouncer.Check(obj)
+}
+
+// Check looks for mistakes in a model, and in the structs nested in it, that
+// would otherwise only show up as a panic while handling a request, or not at
+// all: models that aren't structs, lists or maps, unknown rules, rule parameters that don't parse, dive
+// on fields that aren't lists or maps, comparisons with fields that are
+// missing or can't be compared, unknown options in the tags of profiles (see
+// MethodProfile) and in query and header tags, defaults that don't parse, merge keys that aren't fields, and
+// fields of types that can't be decoded. Models may be given as pointers.
+// It returns a *ModelError listing them, or nil.
+//
+// The handler constructors of b panic with this error, so that a mistake
+// stops the server from starting. Custom rules have to be registered before
+// the handlers using them are created.
+func (b *Bouncer) Check(obj interface{}) error {
+	return b.check(obj, true, b.options)
+}
+
+// check is Check, only accepting lists and maps as models if lists is set,
+// and checking the tags of the profiles used by o.
+func (b *Bouncer) check(obj interface{}, lists bool, o options) error {
+	c := modelChecker{bouncer: b, profiles: o.profileKeys(), seen: map[reflect.Type]bool{}}
+	var typ reflect.Type
+	if obj != nil {
+		typ = modelType(obj)
+	}
+
+	switch {
+	case typ == nil:
+		c.problem("", "the model is nil")
+	case typ.Kind() == reflect.Struct:
+		c.checkStruct(typ, "")
+	case lists && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map):
+		c.checkType(typ, "")
+	case lists:
+		c.problem("", "the model must be a struct, a slice, an array or a map, not a %s", typ)
+	default:
+		c.problem("", "the model must be a struct, not a %s", typ)
+	}
+
+	if len(c.problems) == 0 {
+		return nil
+	}
+	return &ModelError{Model: typ, Problems: c.problems}
+}
+
+// mustCheck panics with the *ModelError describing the mistakes in obj, if
+// any, for a handler with the options o. Lists and maps are only accepted as
+// models if lists is set.
+func (b *Bouncer) mustCheck(obj interface{}, lists bool, o options) {
+	if err := b.check(obj, lists, o); err != nil {
+		panic(err)
+	}
+}
+
+// modelChecker collects the problems found in a model by Check.
+type modelChecker struct {
+	bouncer  *Bouncer
+	profiles []string
+	seen     map[reflect.Type]bool
+	problems []string
+}
+
+func (c *modelChecker) problem(path string, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if path != "" {
+		msg = path + ": " + msg
+	}
+	c.problems = append(c.problems, msg)
+}
+
+// checkStruct checks every field of a struct type, once, and the structs
+// nested in them.
+func (c *modelChecker) checkStruct(typ reflect.Type, path string) {
+	if c.seen[typ] {
+		return
+	}
+	c.seen[typ] = true
+
+	plan := c.bouncer.plan(typ)
+	for i := range plan.fields {
+		f := &plan.fields[i]
+		name := f.field.Name
+		if path != "" {
+			name = path + "." + name
+		}
+
+		// fields that are neither decoded from json nor bound from forms
+		// can hold anything
+		if !f.ignored || !f.skipped {
+			c.checkType(f.field.Type, name)
+		}
+		c.checkTags(f, name)
+		c.checkRules(typ, f, name)
+
+		if err := setDefault(reflect.New(f.field.Type).Elem(), f.field); err != nil {
+			c.problem(name, "invalid default %q: %s", f.field.Tag.Get("default"), err)
+		}
+	}
+}
+
+// checkType reports types that can't be decoded, and checks the structs they hold.
+func (c *modelChecker) checkType(typ reflect.Type, path string) {
+	typ = indirectType(typ)
+	if typ.Kind() == reflect.Struct && typ.Implements(nullableFieldType) {
+		c.checkType(typ.Field(0).Type, path)
+		return
+	}
+	// types that decode themselves, like time.Time, are taken as they are
+	if reflect.PtrTo(typ).Implements(jsonUnmarshalerType) || reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		c.checkStruct(typ, path)
+	case reflect.Slice, reflect.Array:
+		c.checkType(typ.Elem(), path)
+	case reflect.Map:
+		switch typ.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !reflect.PtrTo(typ.Key()).Implements(textUnmarshalerType) {
+				c.problem(path, "maps with %s keys can't be decoded", typ.Key())
+			}
+		}
+		c.checkType(typ.Elem(), path)
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		c.problem(path, "fields of type %s can't be decoded", typ)
+	}
+}
+
+// checkTags reports unknown options in the profile, query and header tags of
+// a field, and merge keys that don't name a field.
+func (c *modelChecker) checkTags(f *fieldPlan, path string) {
+	tags := f.field.Tag
+	for _, key := range c.profiles {
+		if tag := tags.Get(key); tag != "-" {
+			c.checkOptions(path, key, strings.Split(tag, ","), "required", "nonnull")
+		}
+	}
+	for _, key := range []string{"query", "header"} {
+		c.checkOptions(path, key, strings.Split(tags.Get(key), ",")[1:], "required")
+	}
+
+	if tag := tags.Get("merge"); tag != "" {
+		elem := indirectType(f.field.Type)
+		if elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+			elem = indirectType(elem.Elem())
+		}
+		if !strings.HasPrefix(tag, "key=") {
+			c.problem(path, "unknown merge tag %q, expected key=...", tag)
+		} else if _, ok := jsonFieldByName(elem, tag[len("key="):]); elem.Kind() == reflect.Struct && !ok {
+			c.problem(path, "the merge key %q is not a field of %s", tag[len("key="):], elem)
+		}
+	}
+}
+
+// checkOptions reports the options of the tag named key that aren't allowed.
+func (c *modelChecker) checkOptions(path string, key string, options []string, allowed ...string) {
+	for _, option := range options {
+		known := option == ""
+		for _, a := range allowed {
+			known = known || option == a
+		}
+		if !known {
+			c.problem(path, "unknown option %q in the %s tag", option, key)
+		}
+	}
+}
+
+// checkRules reports unknown rules in the validate tag of a field of parent,
+// and rules that can't apply to it.
+func (c *modelChecker) checkRules(parent reflect.Type, f *fieldPlan, path string) {
+	typ := validatedType(f.field.Type)
+	for _, r := range f.rules {
+		if r.dive {
+			switch typ.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				typ = validatedType(typ.Elem())
+				continue
+			case reflect.Interface:
+				// the elements aren't known until a value is decoded
+				return
+			}
+			c.problem(path, "dive on a %s, which is not a slice, array or map", typ)
+			return
+		}
+
+		if !r.known {
+			c.problem(path, "unknown validation rule %q", r.name)
+		} else if r.custom == nil {
+			if msg := checkRuleParam(parent, r, typ); msg != "" {
+				c.problem(path, "%s", msg)
+			}
+		}
+	}
+}
+
+// validatedType is the type rules are checked against for a field of typ.
+func validatedType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Struct && typ.Implements(nullableFieldType) {
+		typ = typ.Field(0).Type
+	}
+	return indirectType(typ)
+}
+
+// checkRuleParam describes what is wrong with the parameter of a built-in rule
+// on a field of type typ, if anything.
+func checkRuleParam(parent reflect.Type, r planRule, typ reflect.Type) string {
+	switch r.name {
+	case "min", "max", "len", "gt", "gte", "lt", "lte":
+		if _, err := strconv.ParseFloat(r.param, 64); err != nil {
+			return fmt.Sprintf("%s takes a number, not %q", r.name, r.param)
+		}
+	case "regex":
+		if _, err := regexp.Compile(r.param); err != nil {
+			return fmt.Sprintf("invalid regex %q: %s", r.param, err)
+		}
+	case "oneof":
+		if strings.TrimSpace(r.param) == "" {
+			return "oneof takes a list of values"
+		}
+	case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+		other, ok := comparedField(parent, r.param)
+		if !ok {
+			return fmt.Sprintf("%s compares with %q, which is not a field of %s", r.name, r.param, parent)
+		}
+		if !comparableTypes(typ, indirectType(other.field.Type)) {
+			return fmt.Sprintf("%s can't compare %s with %s", r.name, typ, other.field.Type)
+		}
+	}
+	return ""
+}
+
+// comparableTypes reports whether compareValues can order values of a and b.
+func comparableTypes(a reflect.Type, b reflect.Type) bool {
+	// what an interface{} holds isn't known until a value is decoded, and a
+	// value that can't be compared is reported then
+	if a.Kind() == reflect.Interface || b.Kind() == reflect.Interface {
+		return true
+	}
+	if a == timeType || b == timeType {
+		return a == b
+	}
+	if a.Kind() == reflect.String || b.Kind() == reflect.String {
+		return a.Kind() == b.Kind()
+	}
+	_, aok := number(reflect.Zero(a))
+	_, bok := number(reflect.Zero(b))
+	return aok && bok
+}
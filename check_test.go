@@ -0,0 +1,100 @@
+package bouncer
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type (
+	// For exercising the mistakes found by Check
+	Misspelled struct {
+		Name     string            `json:"name" create:"requierd" validate:"nonempty"`
+		Code     string            `json:"code" validate:"len=two,regex=[a-"`
+		Starts   int               `json:"starts"`
+		Ends     string            `json:"ends" validate:"gtfield=Starts,ltfield=Finish"`
+		Tags     []string          `json:"tags" validate:"dive,dive,min=1"`
+		Limit    int               `query:"limit,requried" default:"all"`
+		Tenant   string            `header:"X-Tenant,optional"`
+		Lines    []InvoiceLine     `json:"lines" merge:"key=id"`
+		Callback func()            `json:"callback"`
+		Nested   MisspelledNested  `json:"nested"`
+		Opaque   func()            `json:"-" form:"-"`
+		Scores   map[string]string `json:"scores" validate:"oneof="`
+	}
+
+	MisspelledNested struct {
+		Sku string `json:"sku" validate:"skuu"`
+	}
+)
+
+func TestCheck(t *testing.T) {
+	err := Check(Misspelled{})
+	modelErr, ok := err.(*ModelError)
+	if !ok {
+		t.Fatalf("Expected a ModelError, but got '%v'", err)
+	}
+
+	expected := []string{
+		`Name: unknown option "requierd" in the create tag`,
+		`Name: unknown validation rule "nonempty"`,
+		`Code: len takes a number, not "two"`,
+		`Code: invalid regex "[a-"`,
+		`Ends: gtfield can't compare string with int`,
+		`Ends: ltfield compares with "Finish", which is not a field of bouncer.Misspelled`,
+		`Tags: dive on a string, which is not a slice, array or map`,
+		`Limit: unknown option "requried" in the query tag`,
+		`Limit: invalid default "all"`,
+		`Tenant: unknown option "optional" in the header tag`,
+		`Lines: the merge key "id" is not a field of bouncer.InvoiceLine`,
+		`Callback: fields of type func() can't be decoded`,
+		`Nested.Sku: unknown validation rule "skuu"`,
+		`Scores: oneof takes a list of values`,
+	}
+	if len(modelErr.Problems) != len(expected) {
+		t.Errorf("Expected %d problems, but got %d: '%s'", len(expected), len(modelErr.Problems), strings.Join(modelErr.Problems, "\n"))
+	}
+	for _, problem := range expected {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("Expected the problem '%s', but got '%s'", problem, strings.Join(modelErr.Problems, "\n"))
+		}
+	}
+}
+
+func TestCheckValidModels(t *testing.T) {
+	for _, model := range []interface{}{Foo{}, Shop{}, Invoice{}, Team{}, Registration{}, ListParams{}, Payment{}, Post{}, Product{}} {
+		if err := newProductBouncer().Check(model); err != nil {
+			t.Errorf("Expected %T to be valid, but got '%v'", model, err)
+		}
+	}
+}
+
+func TestCheckModels(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		model       interface{}
+		problem     string
+	}{
+		{"Pointer", &MisspelledNested{}, `Sku: unknown validation rule "skuu"`},
+		{"Not a struct", "foo", "the model must be a struct, a slice, an array or a map, not a string"},
+		{"List", []MisspelledNested{}, `Sku: unknown validation rule "skuu"`},
+		{"Nil", nil, "the model is nil"},
+	} {
+		err := Check(testCase.model)
+		if err == nil || !strings.Contains(err.Error(), testCase.problem) {
+			t.Errorf("'%s' should have reported '%s', but got '%v'", testCase.description, testCase.problem, err)
+		}
+	}
+}
+
+func TestHandlerChecksModel(t *testing.T) {
+	defer func() {
+		err, ok := recover().(*ModelError)
+		if !ok || err.Model != reflect.TypeOf(MisspelledNested{}) {
+			t.Errorf("Expected creating a handler for an invalid model to panic with a ModelError, but got '%v'", err)
+		}
+	}()
+
+	NewBouncerHandler(MisspelledNested{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+}
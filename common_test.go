@@ -25,10 +25,9 @@ type (
 
 	// The common function signature of the handlers going under test.
 	handlerFunc    func(interface{}, http.Handler) http.Handler
-	validationFunc func(interface{}, *http.Request) Errors
+	validationFunc func(interface{}, *http.Request) (*http.Request, Errors)
 )
 
 const (
-	testRoute       = "/test"
-	formContentType = "application/x-www-form-urlencoded"
+	testRoute = "/test"
 )
@@ -0,0 +1,87 @@
+package bouncer
+
+import (
+	"context"
+)
+
+// contextKey is unexported so that values stored by bouncer can only be
+// read through the accessors below.
+type contextKey int
+
+const (
+	decodedBodyKey contextKey = iota
+	patchBodyKey
+	patchOperationsKey
+	decodedQueryKey
+)
+
+func withDecodedBody(ctx context.Context, body interface{}) context.Context {
+	return context.WithValue(ctx, decodedBodyKey, body)
+}
+
+func withPatchBody(ctx context.Context, patchJson []byte) context.Context {
+	return context.WithValue(ctx, patchBodyKey, patchJson)
+}
+
+func withPatchOperations(ctx context.Context, operations []Operation) context.Context {
+	return context.WithValue(ctx, patchOperationsKey, operations)
+}
+
+func withDecodedQuery(ctx context.Context, query interface{}) context.Context {
+	return context.WithValue(ctx, decodedQueryKey, query)
+}
+
+// DecodedBody returns the validated body stored by NewBouncerHandler, which
+// is a pointer to a new instance of the model. It returns nil if there is none.
+func DecodedBody(ctx context.Context) interface{} {
+	return ctx.Value(decodedBodyKey)
+}
+
+// Body returns the validated body stored by NewBouncerHandler as a T, which
+// may be the model or a pointer to it.
+// The second result is false if there is no body or it is not a T.
+func Body[T any](ctx context.Context) (T, bool) {
+	return storedAs[T](DecodedBody(ctx))
+}
+
+// PatchBody returns the sanitized patch json stored by NewBouncerPatchHandler,
+// containing only the keys present in the request. For a JSON Patch body it
+// holds the validated operations instead. It returns nil if there is none.
+func PatchBody(ctx context.Context) []byte {
+	patchJson, _ := ctx.Value(patchBodyKey).([]byte)
+	return patchJson
+}
+
+// PatchOperations returns the validated operations of a JSON Patch body stored
+// by NewBouncerPatchHandler. It returns nil if the body was not a JSON Patch.
+func PatchOperations(ctx context.Context) []Operation {
+	operations, _ := ctx.Value(patchOperationsKey).([]Operation)
+	return operations
+}
+
+// DecodedQuery returns the validated query and path parameters stored by
+// NewBouncerQueryHandler, which is a pointer to a new instance of the model.
+// It returns nil if there is none.
+func DecodedQuery(ctx context.Context) interface{} {
+	return ctx.Value(decodedQueryKey)
+}
+
+// Query returns the validated parameters stored by NewBouncerQueryHandler as a
+// T, which may be the model or a pointer to it.
+// The second result is false if there are none or they are not a T.
+func Query[T any](ctx context.Context) (T, bool) {
+	return storedAs[T](DecodedQuery(ctx))
+}
+
+// storedAs converts the pointer stored in a context into a T, which may be
+// the pointer itself for pointer models.
+func storedAs[T any](stored interface{}) (T, bool) {
+	if ptr, ok := stored.(*T); ok && ptr != nil {
+		return *ptr, true
+	}
+	if value, ok := stored.(T); ok {
+		return value, true
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,46 @@
+package bouncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodedBody(t *testing.T) {
+	var foo Foo
+	var ok bool
+	handler := NewBouncerHandler(Foo{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, isFoo := DecodedBody(r.Context()).(*Foo); !isFoo {
+			t.Errorf("Expected DecodedBody to return a *Foo, but got %T", DecodedBody(r.Context()))
+		}
+		foo, ok = Body[Foo](r.Context())
+	}))
+
+	req, _ := http.NewRequest("POST", testRoute, strings.NewReader(`{"title":"Foo Title"}`))
+	req.Header.Set("Content-Type", jsonContentType)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !ok || foo.Title != "Foo Title" {
+		t.Errorf("Expected Body to return the decoded Foo, but got %+v (ok: %v)", foo, ok)
+	}
+
+	if _, ok := Body[Person](req.Context()); ok {
+		t.Errorf("Expected Body to report false for a request without a decoded body")
+	}
+}
+
+func TestPatchBody(t *testing.T) {
+	var patchJson []byte
+	handler := NewBouncerPatchHandler(Foo{}, 1024, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		patchJson = PatchBody(r.Context())
+	}))
+
+	req, _ := http.NewRequest("PATCH", testRoute, strings.NewReader(`{"content":" New Content "}`))
+	req.Header.Set("Content-Type", jsonContentType)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if string(patchJson) != `{"content":"New Content"}` {
+		t.Errorf("Expected the sanitized patch json, but got '%s'", patchJson)
+	}
+}
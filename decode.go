@@ -0,0 +1,271 @@
+package bouncer
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Decoder decodes request bodies of a media type into a model. v is a pointer
+// to a new instance of the model. Register one with RegisterDecoder to accept
+// other formats, such as YAML or MessagePack, with the same model and tags.
+//
+// Bouncer can't tell which keys a Decoder found in the body, so for these
+// bodies a field counts as present when it doesn't have its zero value.
+type Decoder interface {
+	Decode(body io.Reader, v interface{}) error
+}
+
+// DecoderFunc adapts a function, such as a wrapper around yaml.Unmarshal, to a Decoder.
+type DecoderFunc func(body io.Reader, v interface{}) error
+
+// Decode calls f.
+func (f DecoderFunc) Decode(body io.Reader, v interface{}) error {
+	return f(body, v)
+}
+
+// XMLDecoder decodes xml bodies with encoding/xml, so models need xml tags.
+var XMLDecoder Decoder = DecoderFunc(func(body io.Reader, v interface{}) error {
+	return xml.NewDecoder(body).Decode(v)
+})
+
+// RegisterDecoder adds a decoder to DefaultBouncer, see Bouncer.RegisterDecoder.
+func RegisterDecoder(mediaType string, decoder Decoder) {
+	DefaultBouncer.RegisterDecoder(mediaType, decoder)
+}
+
+// RegisterDecoder makes the handlers of b decode bodies of the given media
+// type, e.g. "application/yaml", with decoder. It replaces the built-in
+// decoder if there is one for that media type.
+func (b *Bouncer) RegisterDecoder(mediaType string, decoder Decoder) {
+	if decoder == nil {
+		panic("bouncer: nil Decoder for media type " + mediaType)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.decoders[strings.ToLower(strings.TrimSpace(mediaType))] = decoderBody(decoder)
+}
+
+// decoderBody adapts a Decoder to a bodyDecoder.
+func decoderBody(decoder Decoder) bodyDecoder {
+	return func(obj interface{}, req *http.Request, o options) (interface{}, *presence, Errors) {
+		var errors Errors
+		model := reflect.New(modelType(obj))
+
+		if req.Body != nil {
+			if err := decoder.Decode(req.Body, model.Interface()); err != nil && err != io.EOF {
+				addBodyError(&errors, err)
+			}
+		}
+		return model.Interface(), nil, errors
+	}
+}
+
+// bodyDecoder decodes a request body into a new instance of obj, returning a
+// pointer to it along with the presence of the fields in the body.
+type bodyDecoder func(obj interface{}, req *http.Request, o options) (interface{}, *presence, Errors)
+
+// the decoders available to every Bouncer, keyed by media type
+var builtinDecoders = map[string]bodyDecoder{
+	"application/json": func(obj interface{}, req *http.Request, o options) (interface{}, *presence, Errors) {
+		return decodeJson(obj, req.Body, o)
+	},
+	formContentType:      decodeForm,
+	multipartContentType: decodeForm,
+	"application/xml":    decoderBody(XMLDecoder),
+	"text/xml":           decoderBody(XMLDecoder),
+}
+
+// decodeJson decodes a json body into a new instance of obj, after checking
+// it against the depth and array length limits in o. In strict mode, unknown
+// keys and data after the json value are reported as well.
+func decodeJson(jsonStruct interface{}, reader io.Reader, o options) (interface{}, *presence, Errors) {
+	var errors Errors
+	typ := modelType(jsonStruct)
+	obj := reflect.New(typ)
+	fields := &presence{}
+	strict := o.strict || isStrictModel(typ)
+
+	if reader != nil {
+		jsonData, err := ioutil.ReadAll(reader)
+		if err != nil {
+			addBodyError(&errors, err)
+			return obj.Interface(), fields, errors
+		}
+		if msg := checkJsonLimits(jsonData, o); msg != "" {
+			errors.Add([]string{}, PayloadTooLargeError, msg)
+			return obj.Interface(), fields, errors
+		}
+
+		// encoding/json carries on after a value of the wrong type, which is
+		// reported below along with any others
+		decoder := json.NewDecoder(bytes.NewReader(jsonData))
+		err = decoder.Decode(obj.Interface())
+		typeErr, isTypeErr := err.(*json.UnmarshalTypeError)
+		if err != nil && !isTypeErr {
+			if err != io.EOF {
+				errors.Add([]string{}, DeserializationError, err.Error())
+			}
+			return obj.Interface(), fields, errors
+		}
+		if _, err = decoder.Token(); strict && err != io.EOF {
+			errors.Add([]string{}, DeserializationError, "Unexpected data after the json value")
+			return obj.Interface(), fields, errors
+		}
+
+		var document interface{}
+		documentDecoder := json.NewDecoder(bytes.NewReader(jsonData))
+		documentDecoder.UseNumber()
+		if documentDecoder.Decode(&document) == nil {
+			fields = newPresence(document)
+			checkUnknownFields(&errors, typ, document, rootPath(o), o.strict)
+		}
+		if isTypeErr {
+			before := len(errors)
+			checkJsonTypes(&errors, typ, document, fields, rootPath(o))
+			if len(errors) == before {
+				errors.Add([]string{typeErr.Field}, TypeError, fmt.Sprintf("Expected %s, got %s", typeErr.Type, typeErr.Value))
+			}
+		}
+	}
+
+	return obj.Interface(), fields, errors
+}
+
+// addBodyError reports an error reading or decoding a body, as a
+// PayloadTooLargeError if the body was over its size limit.
+func addBodyError(errs *Errors, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		errs.Add([]string{}, PayloadTooLargeError, fmt.Sprintf("The body must not be larger than %d bytes", maxBytesErr.Limit))
+		return
+	}
+	errs.Add([]string{}, DeserializationError, err.Error())
+}
+
+// checkJsonLimits scans a json document for objects and arrays nested deeper
+// than the limit in o, and for arrays with more items than allowed, returning
+// a message describing the first one found. It doesn't check that the
+// document is valid, which is left to the decoder.
+func checkJsonLimits(jsonData []byte, o options) string {
+	if o.maxDepth <= 0 && o.maxArrayLength <= 0 {
+		return ""
+	}
+
+	// the number of commas seen in each open array, or -1 for objects
+	var open []int
+	inString, escaped := false, false
+	for _, c := range jsonData {
+		if inString {
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			if o.maxDepth > 0 && len(open) >= o.maxDepth {
+				return fmt.Sprintf("The body must not be nested more than %d levels deep", o.maxDepth)
+			}
+			if c == '{' {
+				open = append(open, -1)
+			} else {
+				open = append(open, 0)
+			}
+		case '}', ']':
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		case ',':
+			if n := len(open); n > 0 && open[n-1] >= 0 {
+				open[n-1]++
+				if o.maxArrayLength > 0 && open[n-1] >= o.maxArrayLength {
+					return fmt.Sprintf("Arrays must not have more than %d items", o.maxArrayLength)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// requestMediaType returns the media type of the request body, without its
+// parameters. Bodies without a Content-Type are assumed to be json.
+func requestMediaType(req *http.Request) (string, error) {
+	contentType := req.Header.Get("Content-Type")
+	if contentType == "" {
+		return "application/json", nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return mediaType, err
+}
+
+// isJsonMediaType reports whether a media type is decoded as plain json,
+// including those with a +json suffix such as application/vnd.api+json.
+func isJsonMediaType(mediaType string) bool {
+	return mediaType == "application/json" ||
+		(strings.HasSuffix(mediaType, "+json") && mediaType != jsonPatchContentType)
+}
+
+// accepts reports whether a handler accepting the given media types accepts
+// mediaType. A handler without a list of media types accepts any it can decode.
+func accepts(mediaTypes []string, mediaType string) bool {
+	if mediaTypes == nil {
+		return true
+	}
+	for _, accepted := range mediaTypes {
+		if accepted == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// decoderFor finds the decoder for the body of req, or returns a
+// ContentTypeError if its media type isn't accepted or can't be decoded.
+func (b *Bouncer) decoderFor(req *http.Request, mediaTypes []string) (bodyDecoder, Errors) {
+	var errors Errors
+
+	mediaType, err := requestMediaType(req)
+	if err != nil {
+		errors.Add([]string{}, ContentTypeError, "Invalid Content-Type: "+err.Error())
+		return nil, errors
+	}
+
+	if accepts(mediaTypes, mediaType) {
+		b.mu.RLock()
+		decode, ok := b.decoders[mediaType]
+		b.mu.RUnlock()
+		if ok {
+			return decode, nil
+		}
+		if decode, ok := builtinDecoders[mediaType]; ok {
+			return decode, nil
+		}
+		if isJsonMediaType(mediaType) {
+			return builtinDecoders["application/json"], nil
+		}
+	}
+
+	message := fmt.Sprintf("Unsupported Content-Type %s", mediaType)
+	if mediaTypes != nil {
+		message += ", expected one of: " + strings.Join(mediaTypes, ", ")
+	}
+	errors.Add([]string{}, ContentTypeError, message)
+	return nil, errors
+}
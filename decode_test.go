@@ -0,0 +1,99 @@
+package bouncer
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContentTypes(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, testCase := range []struct {
+		description string
+		method      string
+		handler     http.Handler
+		contentType string
+		payload     string
+		status      int
+	}{
+		{"Json", "POST", NewBouncerHandler(Foo{}, okHandler), jsonContentType, `{"title":"Foo"}`, http.StatusOK},
+		{"Json with a suffix", "POST", NewBouncerHandler(Foo{}, okHandler), "application/vnd.api+json", `{"title":"Foo"}`, http.StatusOK},
+		{"No Content-Type", "POST", NewBouncerHandler(Foo{}, okHandler), "", `{"title":"Foo"}`, http.StatusOK},
+		{"Form", "POST", NewBouncerHandler(Foo{}, okHandler), formContentType, `title=Foo`, http.StatusOK},
+		{"Xml", "POST", NewBouncerHandler(Foo{}, okHandler), "application/xml", `<Foo><Title>Foo</Title></Foo>`, http.StatusOK},
+		{"Xml without a required field", "POST", NewBouncerHandler(Foo{}, okHandler), "text/xml", `<Foo><Content>Foo</Content></Foo>`, StatusUnprocessableEntity},
+		{"Malformed xml", "POST", NewBouncerHandler(Foo{}, okHandler), "application/xml", `<Foo><Title>`, http.StatusBadRequest},
+		{"Unsupported", "POST", NewBouncerHandler(Foo{}, okHandler), "text/plain", `Foo`, http.StatusUnsupportedMediaType},
+		{"Invalid", "POST", NewBouncerHandler(Foo{}, okHandler), "application/json; charset", `{"title":"Foo"}`, http.StatusUnsupportedMediaType},
+		{"Not accepted", "POST", NewBouncerHandler(Foo{}, okHandler, MediaTypes("application/json")), formContentType, `title=Foo`, http.StatusUnsupportedMediaType},
+		{"JSON Patch outside of patch handlers", "POST", NewBouncerHandler(Foo{}, okHandler), jsonPatchContentType, `[]`, http.StatusUnsupportedMediaType},
+		{"JSON Patch not accepted", "PATCH", NewBouncerPatchHandler(Foo{}, 1024, okHandler, MediaTypes("application/json")), jsonPatchContentType, `[]`, http.StatusUnsupportedMediaType},
+		{"Form patch", "PATCH", NewBouncerPatchHandler(Foo{}, 1024, okHandler), formContentType, `content=Foo`, http.StatusOK},
+		{"Xml patch of an immutable field", "PATCH", NewBouncerPatchHandler(Foo{}, 1024, okHandler), "application/xml", `<Foo><Title>Foo</Title></Foo>`, StatusUnprocessableEntity},
+		{"Unsupported patch", "PATCH", NewBouncerPatchHandler(Foo{}, 1024, okHandler), "text/plain", `Foo`, http.StatusUnsupportedMediaType},
+	} {
+		req, _ := http.NewRequest(testCase.method, testRoute, strings.NewReader(testCase.payload))
+		if testCase.contentType != "" {
+			req.Header.Set("Content-Type", testCase.contentType)
+		}
+		recorder := httptest.NewRecorder()
+		testCase.handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != testCase.status {
+			t.Errorf("'%s' should have returned %d, but returned %d with body '%s'",
+				testCase.description, testCase.status, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	// a made up format of "key: value" lines
+	b := New()
+	b.RegisterDecoder("text/x-lines", DecoderFunc(func(body io.Reader, v interface{}) error {
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			if key, value, ok := strings.Cut(scanner.Text(), ": "); ok && key == "title" {
+				v.(*Foo).Title = value
+			}
+		}
+		return scanner.Err()
+	}))
+
+	var decoded Foo
+	handler := b.Handler(Foo{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decoded = *DecodedBody(r.Context()).(*Foo)
+	}))
+
+	for _, testCase := range []struct {
+		payload string
+		status  int
+	}{
+		{"title:  Foo \ncontent: Bar", http.StatusOK},
+		{"content: Bar", StatusUnprocessableEntity},
+	} {
+		req, _ := http.NewRequest("POST", testRoute, strings.NewReader(testCase.payload))
+		req.Header.Set("Content-Type", "text/x-lines; charset=utf-8")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != testCase.status {
+			t.Errorf("'%s' should have returned %d, but returned %d with body '%s'",
+				testCase.payload, testCase.status, recorder.Code, recorder.Body.String())
+		}
+	}
+	if decoded.Title != "Foo" {
+		t.Errorf("Expected the decoded title to be trimmed, but got '%s'", decoded.Title)
+	}
+
+	// the decoder is only registered on b
+	req, _ := http.NewRequest("POST", testRoute, strings.NewReader("title: Foo"))
+	req.Header.Set("Content-Type", "text/x-lines")
+	recorder := httptest.NewRecorder()
+	NewBouncerHandler(Foo{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected DefaultBouncer to reject text/x-lines, but got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package bouncer
+
+import (
+	"testing"
+)
+
+type (
+	// For exercising validation of lists and maps
+	Invoice struct {
+		Lines      []InvoiceLine          `json:"lines" validate:"min=1"`
+		Extras     *[]*InvoiceLine        `json:"extras"`
+		Depots     map[string]Depot       `json:"depots"`
+		Batches    [][]InvoiceLine        `json:"batches"`
+		Quantities []int                  `json:"quantities" validate:"dive,min=1"`
+		Codes      map[string]string      `json:"codes" validate:"max=2,dive,len=2"`
+		Matrix     [][]int                `json:"matrix" validate:"dive,max=2,dive,lte=9"`
+		Notes      []Nullable[string]     `json:"notes" validate:"dive,min=2"`
+		Meta       map[string]interface{} `json:"meta"`
+		Data       interface{}            `json:"data" validate:"dive,min=1"`
+	}
+
+	InvoiceLine struct {
+		Sku  string `json:"sku" create:"required" patch:"nonnull" validate:"len=4"`
+		Note string `json:"note"`
+	}
+
+	Depot struct {
+		City string `json:"city" create:"required"`
+	}
+)
+
+func TestValidateElements(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		method      string
+		payload     string
+		errors      map[string]string
+	}{
+		{"Valid", "POST", `{"lines":[{"sku":" A001 "}],"extras":[{"sku":"B001"},null],"depots":{"oslo":{"city":"Oslo"}},"batches":[[{"sku":"C001"}]],"quantities":[1,2],"codes":{"a":"no"},"matrix":[[1,9]],"notes":["ok",null],"meta":{"a":{}}}`, nil},
+		{"Required in a list", "POST", `{"lines":[{"sku":"A001"},{"note":"x"}]}`, map[string]string{"lines[1].sku": RequiredError}},
+		{"Rule in a list", "POST", `{"lines":[{"sku":"A1"}]}`, map[string]string{"lines[0].sku": LenError}},
+		{"Rule on the list", "POST", `{"lines":[]}`, map[string]string{"lines": MinError}},
+		{"Pointer to a list of pointers", "POST", `{"lines":[{"sku":"A001"}],"extras":[null,{}]}`, map[string]string{"extras[1].sku": RequiredError}},
+		{"Map of structs", "POST", `{"lines":[{"sku":"A001"}],"depots":{"oslo":{}}}`, map[string]string{"depots.oslo.city": RequiredError}},
+		{"List of lists", "POST", `{"lines":[{"sku":"A001"}],"batches":[[],[{"sku":"C1"}]]}`, map[string]string{"batches[1][0].sku": LenError}},
+		{"Dive", "POST", `{"lines":[{"sku":"A001"}],"quantities":[1,0]}`, map[string]string{"quantities[1]": MinError}},
+		{"Dive into a map", "POST", `{"lines":[{"sku":"A001"}],"codes":{"a":"no","b":"yes"}}`, map[string]string{"codes.b": LenError}},
+		{"Rule before a dive", "POST", `{"lines":[{"sku":"A001"}],"codes":{"a":"no","b":"ok","c":"hi"}}`, map[string]string{"codes": MaxError}},
+		{"Dive twice", "POST", `{"lines":[{"sku":"A001"}],"matrix":[[1,2,3],[10]]}`, map[string]string{"matrix[0]": MaxError, "matrix[1][0]": RangeError}},
+		{"Dive into nullables", "POST", `{"lines":[{"sku":"A001"}],"notes":["a"]}`, map[string]string{"notes[0]": MinError}},
+		{"Dive into an interface", "POST", `{"lines":[{"sku":"A001"}],"data":{"a":"x","b":""}}`, map[string]string{"data.b": MinError}},
+		{"Dive into an interface holding a scalar", "POST", `{"lines":[{"sku":"A001"}],"data":"x"}`, map[string]string{"data": TypeError}},
+		{"Type errors skip the dive", "POST", `{"lines":[{"sku":"A001"}],"quantities":[1,"x"]}`, map[string]string{"quantities[1]": TypeError}},
+		{"Patch", "PATCH", `{"lines":[{"note":"x"},{"sku":null}]}`, map[string]string{"lines[1].sku": NullError}},
+	} {
+		_, errs := ValidateJson(Invoice{}, []byte(testCase.payload), testCase.method)
+		if len(errs) != len(testCase.errors) {
+			t.Errorf("'%s' should have returned %d errors, but returned '%+v'", testCase.description, len(testCase.errors), errs)
+			continue
+		}
+		for _, err := range errs {
+			if len(err.FieldNames) != 1 || testCase.errors[err.FieldNames[0]] != err.Classification {
+				t.Errorf("'%s' returned an unexpected error '%+v'", testCase.description, err)
+			}
+		}
+	}
+}
+
+func TestValidateElementsTrims(t *testing.T) {
+	obj, errs := ValidateJson(Invoice{}, []byte(`{"lines":[{"sku":" A001 "}],"depots":{"oslo":{"city":" Oslo "}}}`), "POST")
+	if len(errs) > 0 {
+		t.Fatalf("Expected the invoice to be valid, but got '%+v'", errs)
+	}
+	invoice := obj.(*Invoice)
+	if invoice.Lines[0].Sku != "A001" || invoice.Depots["oslo"].City != "Oslo" {
+		t.Errorf("Expected strings in lists and maps to be trimmed, but got '%+v'", invoice)
+	}
+}
+
+func TestDiveOnAScalar(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected dive on a field that isn't a list or map to panic")
+		}
+	}()
+
+	type Bad struct {
+		Name string `json:"name" validate:"dive,min=1"`
+	}
+	ValidateJson(Bad{}, []byte(`{"name":"a"}`), "POST")
+}
@@ -78,4 +78,20 @@ const (
 	ContentTypeError     = "ContentTypeError"
 	DeserializationError = "DeserializationError"
 	TypeError            = "TypeError"
+	NullError            = "NullError"
+	PathError            = "PathError"
+	PayloadTooLargeError = "PayloadTooLargeError"
+	UnknownFieldError    = "UnknownFieldError"
+
+	// Classifications for the rules in validate tags
+	MinError             = "MinError"
+	MaxError             = "MaxError"
+	LenError             = "LenError"
+	RangeError           = "RangeError"
+	OneOfError           = "OneOfError"
+	RegexError           = "RegexError"
+	EmailError           = "EmailError"
+	URLError             = "URLError"
+	UUIDError            = "UUIDError"
+	FieldComparisonError = "FieldComparisonError"
 )
@@ -0,0 +1,253 @@
+package bouncer
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// jsonField is a field of a struct as encoding/json sees it: either a field
+// of the struct itself, or one promoted from an embedded struct.
+type jsonField struct {
+	// name is the key the field is decoded from, or "-" for ignored fields
+	name string
+
+	// ignored is set for fields tagged `json:"-"`, which aren't decoded from
+	// json but are still bound from forms and validated
+	ignored bool
+
+	// tagged is set when the name comes from a json tag
+	tagged bool
+
+	// index leads to the field through any embedded structs, see
+	// reflect.Value.FieldByIndex
+	index []int
+
+	// field is the struct field itself, with its tags
+	field reflect.StructField
+
+	// quoted is set by the string option, e.g. `json:"id,string"`
+	quoted bool
+}
+
+// the resolved fields of every struct type seen, keyed by type
+var jsonFieldCache sync.Map
+
+// jsonFields returns the fields of a struct type, in the order they are
+// declared, resolved the same way encoding/json does: names are taken from
+// json tags without their options, the fields of embedded structs without a
+// json name are promoted, and of several fields with the same name the least
+// nested one wins, or a tagged one among those equally nested. If that leaves
+// a tie, none of them are decoded.
+func jsonFields(typ reflect.Type) []jsonField {
+	if fields, ok := jsonFieldCache.Load(typ); ok {
+		return fields.([]jsonField)
+	}
+	fields, _ := jsonFieldCache.LoadOrStore(typ, resolveJsonFields(typ))
+	return fields.([]jsonField)
+}
+
+// resolveJsonFields walks a struct type breadth first, one level of embedding
+// at a time, following typeFields in encoding/json.
+func resolveJsonFields(typ reflect.Type) []jsonField {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var current []embedded
+	next := []embedded{{typ: typ}}
+
+	// the number of times each type is embedded at the current and next level
+	count := map[reflect.Type]int{}
+	nextCount := map[reflect.Type]int{}
+	visited := map[reflect.Type]bool{}
+
+	var fields, ignored []jsonField
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				if sf.Anonymous {
+					// embedded structs of unexported types still promote their exported fields
+					if indirectType(sf.Type).Kind() != reflect.Struct && !sf.IsExported() {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					ignored = append(ignored, jsonField{name: "-", ignored: true, index: index, field: sf})
+					continue
+				}
+				name, options := tag, ""
+				if comma := strings.Index(tag, ","); comma > -1 {
+					name, options = tag[:comma], tag[comma:]
+				}
+				if !isValidJsonName(name) {
+					name = ""
+				}
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+
+				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+					field := jsonField{name: name, tagged: name != "", index: index, field: sf}
+					if field.name == "" {
+						field.name = sf.Name
+					}
+					if strings.Contains(options+",", ",string,") {
+						switch ft.Kind() {
+						case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+							reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+							reflect.Float32, reflect.Float64, reflect.String:
+							field.quoted = true
+						}
+					}
+					fields = append(fields, field)
+
+					// a type embedded more than once at this level conflicts with
+					// itself, which a second copy of the field brings out below
+					if count[e.typ] > 1 {
+						fields = append(fields, field)
+					}
+					continue
+				}
+
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, embedded{typ: ft, index: index})
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].name != fields[j].name {
+			return fields[i].name < fields[j].name
+		}
+		if len(fields[i].index) != len(fields[j].index) {
+			return len(fields[i].index) < len(fields[j].index)
+		}
+		if fields[i].tagged != fields[j].tagged {
+			return fields[i].tagged
+		}
+		return indexLess(fields[i].index, fields[j].index)
+	})
+
+	// keep the dominant field of each name
+	resolved := make([]jsonField, 0, len(fields)+len(ignored))
+	for i, advance := 0, 0; i < len(fields); i += advance {
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].name != fields[i].name {
+				break
+			}
+		}
+		if advance == 1 {
+			resolved = append(resolved, fields[i])
+			continue
+		}
+		same := fields[i : i+advance]
+		if len(same[0].index) != len(same[1].index) || same[0].tagged != same[1].tagged {
+			resolved = append(resolved, same[0])
+		}
+	}
+
+	resolved = append(resolved, ignored...)
+	sort.Slice(resolved, func(i, j int) bool {
+		return indexLess(resolved[i].index, resolved[j].index)
+	})
+	return resolved
+}
+
+// indexLess orders index sequences by the order the fields are declared in.
+func indexLess(a []int, b []int) bool {
+	for i := range a {
+		if i >= len(b) {
+			return false
+		}
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// isValidJsonName reports whether encoding/json accepts name from a json tag.
+func isValidJsonName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c) && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// value returns the field in the struct v. Promoted fields may be reached
+// through nil pointers to embedded structs, which are allocated if alloc is
+// set (and they can be), and otherwise make the field missing.
+func (f jsonField) value(v reflect.Value, alloc bool) (reflect.Value, bool) {
+	for i, x := range f.index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// jsonFieldByName finds the field of a struct decoded from the given key,
+// matching it the same way encoding/json does: exactly, or failing that
+// case-insensitively.
+func jsonFieldByName(typ reflect.Type, name string) (jsonField, bool) {
+	var match *jsonField
+	fields := jsonFields(typ)
+	for i := range fields {
+		if fields[i].ignored {
+			continue
+		}
+		if fields[i].name == name {
+			return fields[i], true
+		}
+		if match == nil && strings.EqualFold(fields[i].name, name) {
+			match = &fields[i]
+		}
+	}
+	if match != nil {
+		return *match, true
+	}
+	return jsonField{}, false
+}
+
+// fieldByJsonName is like jsonFieldByName, but returns the struct field.
+func fieldByJsonName(typ reflect.Type, name string) (reflect.StructField, bool) {
+	f, ok := jsonFieldByName(typ, name)
+	return f.field, ok
+}
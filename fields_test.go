@@ -0,0 +1,138 @@
+package bouncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type (
+	// For exercising how fields are resolved from json tags and embedding
+	Post struct {
+		Title    string `json:"title,omitempty" create:"required"`
+		Note     string `json:"note"`
+		Internal string `json:"-" form:"internal"`
+		Stamp
+		*Byline
+		Left
+		Right
+	}
+
+	Stamp struct {
+		CreatedBy string `json:"created_by" create:"required"`
+		Note      string `json:"note" validate:"max=1"`
+	}
+
+	Byline struct {
+		Author string `json:"author" form:"author" validate:"max=5"`
+	}
+
+	Left struct {
+		Side string
+	}
+
+	Right struct {
+		Side string
+	}
+
+	Letter struct {
+		Body string `json:"body"`
+		*Signature
+	}
+
+	Signature struct {
+		Signer string `json:"signer" create:"required"`
+	}
+
+	PostQuery struct {
+		Paging
+	}
+
+	Paging struct {
+		Page int `query:"page" default:"1" validate:"gte=1"`
+	}
+)
+
+func TestJsonFields(t *testing.T) {
+	var names []string
+	for _, f := range jsonFields(reflect.TypeOf(Post{})) {
+		names = append(names, f.name)
+	}
+	expected := []string{"title", "note", "-", "created_by", "author"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected the fields %v, but got %v", expected, names)
+	}
+}
+
+func TestEmbeddedFields(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		payload     string
+		paths       []string
+	}{
+		{"Valid", `{"title":"A","created_by":"b","note":"long","author":"c"}`, nil},
+		{"Json options", `{"created_by":"b"}`, []string{"title"}},
+		{"Promoted required field", `{"title":"A"}`, []string{"created_by"}},
+		{"Promoted through a pointer", `{"title":"A","created_by":"b","author":"Someone"}`, []string{"author"}},
+	} {
+		_, errs := DefaultBouncer.ValidateJson(Post{}, []byte(testCase.payload), "POST")
+		if len(errs) != len(testCase.paths) {
+			t.Errorf("'%s' should have returned %d errors, but returned '%+v'", testCase.description, len(testCase.paths), errs)
+			continue
+		}
+		for i, path := range testCase.paths {
+			if len(errs[i].FieldNames) != 1 || errs[i].FieldNames[0] != path {
+				t.Errorf("'%s' should have returned an error on %s, but returned '%+v'", testCase.description, path, errs[i])
+			}
+		}
+	}
+
+	body, _ := DefaultBouncer.ValidateJson(Post{}, []byte(`{"title":"A","created_by":"b","Side":"x"}`), "POST")
+	post := body.(*Post)
+	if post.Byline != nil || post.Left.Side != "" || post.Right.Side != "" {
+		t.Errorf("Expected conflicting and missing embedded fields to be left alone, but got '%+v'", post)
+	}
+}
+
+func TestRequiredThroughNilPointer(t *testing.T) {
+	_, errs := DefaultBouncer.ValidateJson(Letter{}, []byte(`{"body":"x"}`), "POST")
+	if len(errs) != 1 || !errs.Has(RequiredError) || errs[0].FieldNames[0] != "signer" {
+		t.Errorf("Expected a required field promoted through a nil pointer to be required, but got '%+v'", errs)
+	}
+	if _, errs = DefaultBouncer.ValidateJson(Letter{}, []byte(`{"body":"x","signer":"y"}`), "POST"); len(errs) > 0 {
+		t.Errorf("Expected the letter to be valid, but got '%+v'", errs)
+	}
+}
+
+func TestEmbeddedFormFields(t *testing.T) {
+	values := url.Values{"title": {"A"}, "author": {"Someone"}, "internal": {"x"}}
+	body, errs := DefaultBouncer.validateRequest(Post{}, newFormRequest("POST", values), options{})
+	if len(errs) != 2 || errs[0].FieldNames[0] != "created_by" || errs[1].FieldNames[0] != "author" {
+		t.Fatalf("Expected errors on created_by and author, but got '%+v'", errs)
+	}
+
+	post := body.(*Post)
+	if post.Internal != "x" || post.Byline == nil || post.Author != "Someone" {
+		t.Errorf("Expected the embedded fields to be bound, but got '%+v'", post)
+	}
+}
+
+func TestEmbeddedQueryFields(t *testing.T) {
+	var query *PostQuery
+	handler := NewBouncerQueryHandler(PostQuery{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = DecodedQuery(r.Context()).(*PostQuery)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", testRoute, nil))
+	if query == nil || query.Page != 1 {
+		t.Fatalf("Expected the promoted parameter to be defaulted, but got '%+v'", query)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", testRoute+"?page=0", nil))
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected the promoted parameter to be validated, but got %d", recorder.Code)
+	}
+}
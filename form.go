@@ -0,0 +1,305 @@
+package bouncer
+
+import (
+	"encoding"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	formContentType      = "application/x-www-form-urlencoded"
+	multipartContentType = "multipart/form-data"
+
+	// the memory used for multipart bodies before files are stored on disk
+	multipartMaxMemory = 32 << 20
+)
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decodeForm binds a form-urlencoded or multipart body into a new instance of
+// obj, returning a pointer to it and the presence of the fields in the form.
+func decodeForm(obj interface{}, req *http.Request, o options) (interface{}, *presence, Errors) {
+	var errors Errors
+	model := reflect.New(modelType(obj))
+	if model.Elem().Kind() != reflect.Struct {
+		errors.Add([]string{}, ContentTypeError, "Forms can't be decoded into a list, send json")
+		return model.Interface(), &presence{}, errors
+	}
+
+	var err error
+	var files map[string][]*multipart.FileHeader
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType == multipartContentType {
+		err = req.ParseMultipartForm(multipartMaxMemory)
+		if err == nil {
+			files = req.MultipartForm.File
+		}
+	} else {
+		err = req.ParseForm()
+	}
+	if err != nil {
+		addBodyError(&errors, err)
+		return model.Interface(), &presence{}, errors
+	}
+
+	fields := bindForm(&errors, model.Elem(), "", req.PostForm, files)
+	return model.Interface(), fields, errors
+}
+
+// bindForm sets the fields of a struct from form values and files, and returns
+// the presence of the fields that were found. Fields are named by their form
+// tag, falling back to their json name, and nested structs are prefixed by the
+// name of their field, e.g. "address.city". Slices are bound from repeated
+// keys, or "lines[0].sku" style keys for slices of structs. The presence is
+// keyed by json name, like that of a json body, and by go name for the fields
+// tagged json:"-".
+func bindForm(errors *Errors, val reflect.Value, prefix string, values map[string][]string, files map[string][]*multipart.FileHeader) *presence {
+	fields := &presence{keys: map[string]*presence{}}
+
+	// the fields of embedded structs are promoted, like in json
+	for _, f := range jsonFields(val.Type()) {
+		field := f.field
+		if field.Tag.Get("form") == "-" || field.Tag.Get("header") != "" {
+			continue
+		}
+		name := prefix + formName(field)
+
+		// embedded structs behind nil pointers are only allocated when needed
+		target, ok := f.value(val, false)
+		if !ok {
+			if _, sent := values[name]; !sent && len(files[name]) == 0 {
+				continue
+			}
+			if target, ok = f.value(val, true); !ok {
+				continue
+			}
+		}
+		if !target.CanSet() {
+			continue
+		}
+
+		if fieldFiles, ok := files[name]; ok && len(fieldFiles) > 0 {
+			switch field.Type {
+			case fileHeaderType:
+				target.Set(reflect.ValueOf(fieldFiles[0]))
+				fields.setField(f, &presence{})
+			case reflect.SliceOf(fileHeaderType):
+				target.Set(reflect.ValueOf(fieldFiles))
+				fields.setField(f, &presence{})
+			}
+			continue
+		}
+
+		if fieldValues, ok := values[name]; ok {
+			present := &presence{}
+			if err := setFormValues(target, fieldValues); err != nil {
+				errors.Add([]string{name}, TypeError, err.Error())
+				present.invalid = true
+			}
+			fields.setField(f, present)
+			continue
+		}
+
+		elemType := indirectType(field.Type)
+		switch {
+		case isFormStruct(elemType):
+			nested := reflect.New(elemType).Elem()
+			present := bindForm(errors, nested, name+".", values, files)
+			if len(present.keys) > 0 {
+				setIndirect(target, nested)
+				fields.setField(f, present)
+			}
+		case elemType.Kind() == reflect.Slice && isFormStruct(indirectType(elemType.Elem())):
+			if items, present := bindFormList(errors, elemType, name, values, files); present != nil {
+				setIndirect(target, items)
+				fields.setField(f, present)
+			}
+		}
+	}
+	return fields
+}
+
+// bindFormList binds a slice of structs from keys like "lines[0].sku".
+// It returns a nil presence if there were no keys for the list.
+func bindFormList(errors *Errors, typ reflect.Type, name string, values map[string][]string, files map[string][]*multipart.FileHeader) (reflect.Value, *presence) {
+	indexes := map[int]bool{}
+	addIndex := func(key string) {
+		if !strings.HasPrefix(key, name+"[") {
+			return
+		}
+		rest := key[len(name)+1:]
+		if end := strings.Index(rest, "]"); end > 0 {
+			if index, err := strconv.Atoi(rest[:end]); err == nil && index >= 0 {
+				indexes[index] = true
+			}
+		}
+	}
+	for key := range values {
+		addIndex(key)
+	}
+	for key := range files {
+		addIndex(key)
+	}
+	if len(indexes) == 0 {
+		return reflect.Value{}, nil
+	}
+
+	// items are kept in order, without gaps for missing indexes
+	sorted := make([]int, 0, len(indexes))
+	for index := range indexes {
+		sorted = append(sorted, index)
+	}
+	sort.Ints(sorted)
+
+	items := reflect.MakeSlice(typ, len(sorted), len(sorted))
+	present := &presence{}
+	for i, index := range sorted {
+		item := reflect.New(indirectType(typ.Elem())).Elem()
+		present.items = append(present.items, bindForm(errors, item, fmt.Sprintf("%s[%d].", name, index), values, files))
+		setIndirect(items.Index(i), item)
+	}
+	return items, present
+}
+
+// setFormValues converts form values to the type of target.
+func setFormValues(target reflect.Value, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	if target.Kind() == reflect.Struct && target.Type().Implements(nullableFieldType) {
+		target.FieldByName("Set").SetBool(true)
+		return setFormValues(target.FieldByName("Value"), values)
+	}
+
+	if target.Kind() == reflect.Ptr {
+		value := reflect.New(target.Type().Elem())
+		if err := setFormValues(value.Elem(), values); err != nil {
+			return err
+		}
+		target.Set(value)
+		return nil
+	}
+
+	if target.Kind() == reflect.Slice && !reflect.PtrTo(target.Type()).Implements(textUnmarshalerType) {
+		items := reflect.MakeSlice(target.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setFormValue(items.Index(i), value); err != nil {
+				return err
+			}
+		}
+		target.Set(items)
+		return nil
+	}
+
+	return setFormValue(target, values[0])
+}
+
+// setFormValue converts a single form value to the type of target.
+func setFormValue(target reflect.Value, value string) error {
+	if target.Kind() == reflect.Ptr {
+		item := reflect.New(target.Type().Elem())
+		if err := setFormValue(item.Elem(), value); err != nil {
+			return err
+		}
+		target.Set(item)
+		return nil
+	}
+
+	if target.CanAddr() && target.Addr().Type().Implements(textUnmarshalerType) {
+		if err := target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("Expected %s, got %q", target.Type(), value)
+		}
+		return nil
+	}
+
+	var err error
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+		return nil
+	case reflect.Bool:
+		// checkboxes are sent as "on" by browsers
+		if value == "on" {
+			value = "true"
+		}
+		var b bool
+		if b, err = strconv.ParseBool(value); err == nil {
+			target.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if target.Type() == reflect.TypeOf(time.Duration(0)) {
+			var d time.Duration
+			if d, err = time.ParseDuration(value); err == nil {
+				target.SetInt(int64(d))
+				return nil
+			}
+			break
+		}
+		var n int64
+		if n, err = strconv.ParseInt(value, 10, target.Type().Bits()); err == nil {
+			target.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(value, 10, target.Type().Bits()); err == nil {
+			target.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		if n, err = strconv.ParseFloat(value, target.Type().Bits()); err == nil {
+			target.SetFloat(n)
+			return nil
+		}
+	default:
+		return fmt.Errorf("Cannot bind a form value to %s", target.Type())
+	}
+	return fmt.Errorf("Expected %s, got %q", target.Type(), value)
+}
+
+// formName returns the name of a field in a form.
+func formName(field reflect.StructField) string {
+	if f := field.Tag.Get("form"); f != "" {
+		return f
+	}
+	return jsonName(field)
+}
+
+// isFormStruct reports whether a struct is bound field by field, rather than
+// from a single value like time.Time.
+func isFormStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct &&
+		!typ.Implements(nullableFieldType) &&
+		!reflect.PtrTo(typ).Implements(textUnmarshalerType)
+}
+
+// indirectType strips pointers from a type.
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// setIndirect sets target to value, allocating pointers along the way if needed.
+func setIndirect(target reflect.Value, value reflect.Value) {
+	for target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+	target.Set(value)
+}
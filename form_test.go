@@ -0,0 +1,152 @@
+package bouncer
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type (
+	// For exercising form bodies
+	Registration struct {
+		Name      string                  `form:"name" create:"required"`
+		Age       int                     `form:"age" validate:"gte=18"`
+		Subscribe bool                    `form:"subscribe"`
+		Tags      []string                `form:"tags"`
+		Born      time.Time               `form:"born"`
+		Address   Address                 `form:"address"`
+		Lines     []OrderLine             `form:"lines"`
+		Avatar    *multipart.FileHeader   `form:"avatar"`
+		Documents []*multipart.FileHeader `form:"documents"`
+		Secret    string                  `form:"-"`
+	}
+
+	Address struct {
+		City string `json:"city" create:"required"`
+		Zip  string `json:"zip"`
+	}
+)
+
+func newFormRequest(method string, values url.Values) *http.Request {
+	req, _ := http.NewRequest(method, testRoute, strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", formContentType)
+	return req
+}
+
+func TestFormBinding(t *testing.T) {
+	values := url.Values{
+		"name":              {"  Foo  "},
+		"age":               {"30"},
+		"subscribe":         {"on"},
+		"tags":              {"a", "b"},
+		"born":              {"1990-01-02T00:00:00Z"},
+		"address.city":      {"Springfield"},
+		"lines[1].sku":      {"b"},
+		"lines[0].sku":      {"a"},
+		"lines[0].quantity": {"2"},
+		"Secret":            {"x"},
+	}
+
+	body, errs := DefaultBouncer.validateRequest(Registration{}, newFormRequest("POST", values), options{})
+	if len(errs) > 0 {
+		t.Fatalf("Expected the form to be valid, but got '%+v'", errs)
+	}
+
+	registration := body.(*Registration)
+	if registration.Name != "Foo" || registration.Age != 30 || !registration.Subscribe ||
+		len(registration.Tags) != 2 || registration.Born.Year() != 1990 || registration.Address.City != "Springfield" ||
+		registration.Secret != "" {
+		t.Errorf("Expected the form to be bound, but got %+v", registration)
+	}
+	if len(registration.Lines) != 2 || registration.Lines[0].Quantity != 2 || registration.Lines[1].Sku != "b" {
+		t.Errorf("Expected the lines to be bound in order, but got %+v", registration.Lines)
+	}
+}
+
+func TestFormValidation(t *testing.T) {
+	for _, testCase := range []struct {
+		description    string
+		values         url.Values
+		classification string
+	}{
+		{"Missing required field", url.Values{"address.city": {"Springfield"}}, RequiredError},
+		{"Missing required nested field", url.Values{"name": {"Foo"}, "address.zip": {"12345"}}, RequiredError},
+		{"Rule on bound value", url.Values{"name": {"Foo"}, "address.city": {"Springfield"}, "age": {"17"}}, RangeError},
+		{"Value of the wrong type", url.Values{"name": {"Foo"}, "address.city": {"Springfield"}, "age": {"old"}}, TypeError},
+	} {
+		_, errs := DefaultBouncer.validateRequest(Registration{}, newFormRequest("POST", testCase.values), options{})
+		if len(errs) != 1 || !errs.Has(testCase.classification) {
+			t.Errorf("'%s' should have failed with a single %s, but returned '%+v'",
+				testCase.description, testCase.classification, errs)
+		}
+	}
+}
+
+func TestMultipartBinding(t *testing.T) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("name", "Foo")
+	writer.WriteField("address.city", "Springfield")
+	avatar, _ := writer.CreateFormFile("avatar", "avatar.png")
+	avatar.Write([]byte("png"))
+	for _, name := range []string{"a.pdf", "b.pdf"} {
+		document, _ := writer.CreateFormFile("documents", name)
+		document.Write([]byte("pdf"))
+	}
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", testRoute, &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	body, errs := DefaultBouncer.validateRequest(Registration{}, req, options{})
+	if len(errs) > 0 {
+		t.Fatalf("Expected the form to be valid, but got '%+v'", errs)
+	}
+
+	registration := body.(*Registration)
+	if registration.Avatar == nil || registration.Avatar.Filename != "avatar.png" || len(registration.Documents) != 2 {
+		t.Errorf("Expected the files to be bound, but got %+v", registration)
+	}
+}
+
+func TestFormPatch(t *testing.T) {
+	var patchJson []byte
+	handler := NewBouncerPatchHandler(Registration{}, 1024, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		patchJson = PatchBody(r.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), newFormRequest("PATCH", url.Values{"age": {"30"}, "address.zip": {" 12345 "}}))
+
+	expected := `{"Address":{"zip":"12345"},"Age":30}`
+	if string(patchJson) != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, patchJson)
+	}
+}
+
+// For exercising forms binding fields tagged json:"-"
+type Signup struct {
+	Id       int64  `json:"-" create:"-"`
+	Internal string `json:"-" form:"internal"`
+	Email    string `form:"email" create:"required"`
+}
+
+func TestFormIgnoredFields(t *testing.T) {
+	values := url.Values{"email": {"foo@example.com"}, "internal": {"abc"}}
+	body, errs := DefaultBouncer.validateRequest(Signup{}, newFormRequest("POST", values), options{})
+	if len(errs) > 0 {
+		t.Fatalf("Expected binding one field tagged json:\"-\" to leave the others unsent, but got '%+v'", errs)
+	}
+	if signup := body.(*Signup); signup.Internal != "abc" {
+		t.Errorf("Expected the ignored field to be bound from its form name, but got %+v", signup)
+	}
+
+	values = url.Values{"email": {"foo@example.com"}, "-": {"5"}}
+	if _, errs = DefaultBouncer.validateRequest(Signup{}, newFormRequest("POST", values), options{}); len(errs) != 1 || !errs.Has(ImmutableError) {
+		t.Errorf("Expected an immutable field bound from a form to be rejected, but got '%+v'", errs)
+	}
+}
@@ -0,0 +1,76 @@
+package bouncer
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// bindHeaders sets the fields of obj tagged with a header name, e.g.
+// `header:"X-Tenant-ID"`, from the request headers and checks their rules.
+// Headers replace anything decoded from the body into these fields, so they
+// can't be spoofed by the body. `header:"X-Tenant-ID,required"` rejects
+// requests without the header. Errors are reported with the header name.
+// Only the fields of the top level model, and those promoted from its
+// embedded structs, are bound.
+func (b *Bouncer) bindHeaders(ctx context.Context, errors Errors, obj interface{}, header http.Header) Errors {
+	val := reflect.ValueOf(obj)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return errors
+	}
+	val = val.Elem()
+
+	plan := b.plan(val.Type())
+	for i := range plan.fields {
+		f := &plan.fields[i]
+		name, required := f.header, f.headerRequired
+		if name == "" {
+			continue
+		}
+
+		values := header.Values(name)
+		target, ok := f.value(val, len(values) > 0)
+		if ok && target.CanSet() {
+			target.Set(reflect.Zero(f.field.Type))
+		}
+		if len(values) == 0 {
+			if required {
+				errors.Add([]string{name}, RequiredError, "Required")
+			}
+			continue
+		}
+		if !ok || !target.CanSet() {
+			continue
+		}
+
+		if !f.notrim {
+			trimmed := make([]string, len(values))
+			for j, value := range values {
+				trimmed[j] = strings.TrimSpace(value)
+			}
+			values = trimmed
+		}
+		if err := setFormValues(target, values); err != nil {
+			errors.Add([]string{name}, TypeError, err.Error())
+			continue
+		}
+		errors = b.validateRules(ctx, errors, fieldPath{path: name}, f, f.actual(target), val, nil)
+	}
+	return errors
+}
+
+// headerName returns the name of the header a field is bound from, if any,
+// and whether it is required.
+func headerName(field reflect.StructField) (string, bool) {
+	parts := strings.Split(field.Tag.Get("header"), ",")
+	if parts[0] == "" || parts[0] == "-" {
+		return "", false
+	}
+	for _, option := range parts[1:] {
+		if option == "required" {
+			return parts[0], true
+		}
+	}
+	return parts[0], false
+}
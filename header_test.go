@@ -0,0 +1,84 @@
+package bouncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type (
+	// For exercising header binding
+	Payment struct {
+		TenantID       string `json:"tenant_id" header:"X-Tenant-ID,required" validate:"uuid"`
+		IdempotencyKey string `json:"-" header:"Idempotency-Key" validate:"min=8"`
+		Attempt        int    `json:"-" header:"X-Attempt"`
+		Amount         int    `json:"amount" create:"required" patch:"nonnull"`
+	}
+)
+
+const testTenant = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+
+func TestHeaders(t *testing.T) {
+	for _, testCase := range []struct {
+		description    string
+		method         string
+		headers        map[string]string
+		payload        string
+		classification string
+		field          string
+	}{
+		{"Valid", "POST", map[string]string{"X-Tenant-ID": testTenant, "Idempotency-Key": "abcdefgh"}, `{"amount":5}`, "", ""},
+		{"Missing a required header", "POST", map[string]string{}, `{"amount":5}`, RequiredError, "X-Tenant-ID"},
+		{"Tenant only in the body", "POST", map[string]string{}, `{"tenant_id":"` + testTenant + `","amount":5}`, RequiredError, "X-Tenant-ID"},
+		{"Breaking a rule", "POST", map[string]string{"X-Tenant-ID": "tenant"}, `{"amount":5}`, UUIDError, "X-Tenant-ID"},
+		{"Breaking a rule on an optional header", "POST", map[string]string{"X-Tenant-ID": testTenant, "Idempotency-Key": "abc"}, `{"amount":5}`, MinError, "Idempotency-Key"},
+		{"Not a number", "POST", map[string]string{"X-Tenant-ID": testTenant, "X-Attempt": "one"}, `{"amount":5}`, TypeError, "X-Attempt"},
+		{"Body errors alongside", "POST", map[string]string{}, `{}`, RequiredError, "amount"},
+		{"Patch", "PATCH", map[string]string{"X-Tenant-ID": testTenant}, `{"amount":5}`, "", ""},
+		{"Patch without a required header", "PATCH", map[string]string{}, `{"amount":5}`, RequiredError, "X-Tenant-ID"},
+		{"JSON Patch without a required header", "PATCH", map[string]string{"Content-Type": jsonPatchContentType}, `[]`, RequiredError, "X-Tenant-ID"},
+		{"JSON Patch of a header field", "PATCH", map[string]string{"Content-Type": jsonPatchContentType, "X-Tenant-ID": testTenant},
+			`[{"op":"replace","path":"/tenant_id","value":"evil"}]`, ImmutableError, "/tenant_id"},
+	} {
+		handler := NewBouncerHandler(Payment{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		if testCase.method == "PATCH" {
+			handler = NewBouncerPatchHandler(Payment{}, 1024, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		}
+
+		req, _ := http.NewRequest(testCase.method, testRoute, strings.NewReader(testCase.payload))
+		req.Header.Set("Content-Type", jsonContentType)
+		for key, value := range testCase.headers {
+			req.Header.Set(key, value)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if testCase.classification == "" && recorder.Code != http.StatusOK {
+			t.Errorf("'%s' should have been valid, but returned %d with body '%s'", testCase.description, recorder.Code, recorder.Body.String())
+		} else if testCase.classification != "" && !strings.Contains(recorder.Body.String(),
+			`{"fieldNames":["`+testCase.field+`"],"classification":"`+testCase.classification+`"`) {
+			t.Errorf("'%s' should have returned a %s on %s, but returned %d with body '%s'",
+				testCase.description, testCase.classification, testCase.field, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestHeadersReplaceBody(t *testing.T) {
+	handler := NewHandler(func(w http.ResponseWriter, r *http.Request, payment Payment) {
+		if payment.TenantID != testTenant || payment.Attempt != 2 || payment.Amount != 5 {
+			t.Errorf("Expected the headers to be bound into the body, but got '%+v'", payment)
+		}
+	})
+
+	req, _ := http.NewRequest("POST", testRoute, strings.NewReader(`{"tenant_id":"00000000-0000-0000-0000-000000000000","amount":5}`))
+	req.Header.Set("Content-Type", jsonContentType)
+	req.Header.Set("X-Tenant-ID", " "+testTenant)
+	req.Header.Set("X-Attempt", "2")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected the request to be valid, but got %d with body '%s'", recorder.Code, recorder.Body.String())
+	}
+}
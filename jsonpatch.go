@@ -0,0 +1,239 @@
+package bouncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const jsonPatchContentType = "application/json-patch+json"
+
+// Operation is a single operation of a JSON Patch (RFC 6902) body.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// rawOperation is used to tell missing members apart from empty ones.
+type rawOperation struct {
+	Op    *string         `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// patchTarget is what a JSON Pointer resolves to in a model.
+type patchTarget struct {
+	// typ is the type of the value at the pointer
+	typ reflect.Type
+
+	// field is set when the pointer ends on a struct field, with parent
+	// being the struct it belongs to
+	field  *fieldPlan
+	parent reflect.Type
+
+	// immutable is set when the pointer is, or is inside, a field that is
+	// immutable in the profile, e.g. patch:"-", or bound from a header
+	immutable bool
+}
+
+// validateJsonPatch checks every operation of a JSON Patch against the model:
+// each path has to resolve through its json tags, operations can't modify
+// fields that are immutable in the profile, e.g. patch:"-", or bound from
+// headers, and values have to fit the type of the field they are written to
+// and pass its rules.
+// Errors are reported with the path of the offending operation.
+func (b *Bouncer) validateJsonPatch(ctx context.Context, model interface{}, profile string, jsonData []byte) ([]Operation, Errors) {
+	var errors Errors
+	typ := modelType(model)
+
+	var rawOperations []rawOperation
+	if err := json.Unmarshal(jsonData, &rawOperations); err != nil {
+		errors.Add([]string{}, DeserializationError, "A JSON Patch must be an array of operations: "+err.Error())
+		return nil, errors
+	}
+
+	operations := make([]Operation, 0, len(rawOperations))
+	for i, raw := range rawOperations {
+		if raw.Op == nil || raw.Path == nil {
+			errors.Add([]string{}, DeserializationError, fmt.Sprintf("Operation %d must have an op and a path", i))
+			continue
+		}
+		op := Operation{Op: *raw.Op, Path: *raw.Path, Value: raw.Value}
+
+		switch op.Op {
+		case "add", "replace", "test":
+			if raw.Value == nil {
+				errors.Add([]string{op.Path}, DeserializationError, fmt.Sprintf("A %s operation must have a value", op.Op))
+				continue
+			}
+			target, ok := b.resolvePointer(&errors, typ, profile, op.Path, op.Op == "add")
+			if !ok {
+				continue
+			}
+			if op.Op != "test" && target.immutable {
+				errors.Add([]string{op.Path}, ImmutableError, "Immutable")
+				continue
+			}
+			op.Value = b.checkPatchValue(ctx, &errors, profile, op, target)
+		case "remove":
+			target, ok := b.resolvePointer(&errors, typ, profile, op.Path, false)
+			if !ok {
+				continue
+			}
+			if target.immutable {
+				errors.Add([]string{op.Path}, ImmutableError, "Immutable")
+			} else if target.field != nil && target.field.mode(profile).nonnull {
+				errors.Add([]string{op.Path}, NullError, "Must not be null")
+			}
+		case "move", "copy":
+			if raw.From == nil {
+				errors.Add([]string{op.Path}, DeserializationError, fmt.Sprintf("A %s operation must have a from", op.Op))
+				continue
+			}
+			op.From = *raw.From
+			from, ok := b.resolvePointer(&errors, typ, profile, op.From, false)
+			if !ok {
+				continue
+			}
+			target, ok := b.resolvePointer(&errors, typ, profile, op.Path, true)
+			if !ok {
+				continue
+			}
+			if op.Op == "move" && from.immutable {
+				errors.Add([]string{op.From}, ImmutableError, "Immutable")
+			}
+			if target.immutable {
+				errors.Add([]string{op.Path}, ImmutableError, "Immutable")
+			}
+			if !from.typ.AssignableTo(target.typ) {
+				errors.Add([]string{op.Path}, TypeError, fmt.Sprintf("Cannot %s %s to %s", op.Op, from.typ, target.typ))
+			}
+		default:
+			errors.Add([]string{op.Path}, DeserializationError, fmt.Sprintf("Unknown operation %q", op.Op))
+			continue
+		}
+
+		operations = append(operations, op)
+	}
+
+	return operations, errors
+}
+
+// checkPatchValue decodes the value of an add, replace or test operation into
+// the type at its path and validates it, returning the sanitized value.
+func (b *Bouncer) checkPatchValue(ctx context.Context, errors *Errors, profile string, op Operation, target patchTarget) json.RawMessage {
+	if strings.TrimSpace(string(op.Value)) == "null" {
+		if target.field != nil && target.field.mode(profile).nonnull {
+			errors.Add([]string{op.Path}, NullError, "Must not be null")
+		}
+		return op.Value
+	}
+
+	value := reflect.New(target.typ)
+	if err := json.Unmarshal(op.Value, value.Interface()); err != nil {
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			errors.Add([]string{op.Path}, TypeError, fmt.Sprintf("Expected %s, got %s", typeErr.Type, typeErr.Value))
+		} else {
+			errors.Add([]string{op.Path}, TypeError, err.Error())
+		}
+		return op.Value
+	}
+	if op.Op == "test" {
+		return op.Value
+	}
+
+	actual := unwrapNullable(indirect(value.Elem()))
+	if target.field != nil && !target.field.notrim && actual.Kind() == reflect.String && actual.CanSet() {
+		actual.SetString(strings.TrimSpace(actual.String()))
+	}
+
+	// objects are validated like the body of a merge patch, as are those in lists and maps
+	path := fieldPath{notation: PointerNotation, path: op.Path}
+	present := presenceFromJson(op.Value)
+	if actual.Kind() == reflect.Struct && !reflect.PtrTo(actual.Type()).Implements(jsonUnmarshalerType) {
+		*errors = b.validateStruct(ctx, *errors, profile, actual.Addr().Interface(), present, path)
+	} else {
+		*errors = b.validateElements(ctx, *errors, profile, actual, present, path)
+	}
+
+	if target.field != nil {
+		*errors = b.validateRules(ctx, *errors, path, target.field, actual, reflect.New(target.parent).Elem(), present)
+	}
+
+	sanitized, err := json.Marshal(value.Interface())
+	if err != nil {
+		return op.Value
+	}
+	// keep only the keys that were sent, like a merge patch
+	if sanitized, err = (merger{keepNulls: true}).createEncodedInterfaceFromOriginal(op.Value, sanitized); err != nil {
+		return op.Value
+	}
+	return sanitized
+}
+
+// resolvePointer follows a JSON Pointer (RFC 6901) through the json tags of typ.
+// The last token may be "-" when appending to a list. If the pointer can't be
+// resolved, an error is added and ok is false.
+func (b *Bouncer) resolvePointer(errors *Errors, typ reflect.Type, profile string, pointer string, appending bool) (target patchTarget, ok bool) {
+	target.typ = typ
+	if pointer == "" {
+		return target, true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		errors.Add([]string{pointer}, DeserializationError, "A path must be empty or start with /")
+		return target, false
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		last := i == len(tokens)-1
+
+		for target.typ.Kind() == reflect.Ptr {
+			target.typ = target.typ.Elem()
+		}
+		if target.typ.Implements(nullableFieldType) && target.typ.Kind() == reflect.Struct {
+			target.typ = target.typ.Field(0).Type
+		}
+		target.field = nil
+		target.parent = nil
+
+		switch target.typ.Kind() {
+		case reflect.Struct:
+			field, found := b.plan(target.typ).lookup(token)
+			if !found {
+				errors.Add([]string{pointer}, PathError, "Unknown path")
+				return target, false
+			}
+			// fields bound from headers can't be set by the body
+			if field.mode(profile).immutable || field.header != "" {
+				target.immutable = true
+			}
+			target.parent = target.typ
+			target.field = field
+			target.typ = field.field.Type
+			continue
+		case reflect.Slice, reflect.Array:
+			if index, err := strconv.Atoi(token); (err == nil && index >= 0 && strconv.Itoa(index) == token) ||
+				(token == "-" && last && appending) {
+				target.typ = target.typ.Elem()
+				continue
+			}
+		case reflect.Map:
+			if target.typ.Key().Kind() == reflect.String {
+				target.typ = target.typ.Elem()
+				continue
+			}
+		}
+
+		errors.Add([]string{pointer}, PathError, "Unknown path")
+		return target, false
+	}
+	return target, true
+}
@@ -0,0 +1,92 @@
+package bouncer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// For exercising JSON Patch bodies
+type Catalog struct {
+	Id       int64             `json:"id" patch:"-"`
+	Name     string            `json:"name" patch:"nonnull" validate:"min=3"`
+	Tags     []string          `json:"tags"`
+	Owner    Person            `json:"owner"`
+	Prices   map[string]int64  `json:"prices"`
+	Aliases  []string          `json:"aliases"`
+	Nickname Nullable[string]  `json:"nickname"`
+	Meta     map[string]string `json:"a/b"`
+}
+
+var jsonPatchTestCases = []ruleTestCase{
+	{"Valid operations", `[
+		{"op":"replace","path":"/name","value":"  Catalog  "},
+		{"op":"add","path":"/tags/-","value":"new"},
+		{"op":"add","path":"/prices/usd","value":3},
+		{"op":"remove","path":"/owner/email"},
+		{"op":"copy","from":"/tags","path":"/aliases"},
+		{"op":"move","from":"/tags/0","path":"/aliases/1"},
+		{"op":"test","path":"/id","value":1},
+		{"op":"replace","path":"/nickname","value":null},
+		{"op":"add","path":"/a~1b/c","value":"d"},
+		{"op":"replace","path":"/owner","value":{"name":"Foo"}}
+	]`, ""},
+	{"Not an array", `{"op":"remove","path":"/name"}`, DeserializationError},
+	{"Unknown operation", `[{"op":"frobnicate","path":"/name"}]`, DeserializationError},
+	{"Missing value", `[{"op":"add","path":"/name"}]`, DeserializationError},
+	{"Missing from", `[{"op":"move","path":"/name"}]`, DeserializationError},
+	{"Unknown field", `[{"op":"replace","path":"/title","value":"Foo"}]`, PathError},
+	{"Invalid index", `[{"op":"replace","path":"/tags/first","value":"Foo"}]`, PathError},
+	{"Append outside of add", `[{"op":"replace","path":"/tags/-","value":"Foo"}]`, PathError},
+	{"Replace immutable field", `[{"op":"replace","path":"/id","value":2}]`, ImmutableError},
+	{"Move from immutable field", `[{"op":"move","from":"/id","path":"/prices/id"}]`, ImmutableError},
+	{"Remove nonnull field", `[{"op":"remove","path":"/name"}]`, NullError},
+	{"Null for nonnull field", `[{"op":"replace","path":"/name","value":null}]`, NullError},
+	{"Wrong type", `[{"op":"add","path":"/prices/usd","value":"three"}]`, TypeError},
+	{"Incompatible copy", `[{"op":"copy","from":"/name","path":"/tags"}]`, TypeError},
+	{"Rules on values", `[{"op":"replace","path":"/name","value":"  ab  "}]`, MinError},
+	{"Rules inside objects", `[{"op":"replace","path":"/owner","value":{"email":"foo@example.com"}}]`, ""},
+}
+
+func TestJsonPatch(t *testing.T) {
+	for _, testCase := range jsonPatchTestCases {
+		_, errs := DefaultBouncer.validateJsonPatch(context.Background(), Catalog{}, "patch", []byte(testCase.payload))
+		if testCase.classification == "" && len(errs) > 0 {
+			t.Errorf("'%s' should have succeeded, but returned errors '%+v'", testCase.description, errs)
+		} else if testCase.classification != "" && (len(errs) != 1 || !errs.Has(testCase.classification)) {
+			t.Errorf("'%s' should have failed with a single %s, but returned '%+v'",
+				testCase.description, testCase.classification, errs)
+		}
+	}
+}
+
+func TestJsonPatchHandler(t *testing.T) {
+	var operations []Operation
+	var patchJson []byte
+	handler := NewBouncerPatchHandler(Catalog{}, 1024, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operations = PatchOperations(r.Context())
+		patchJson = PatchBody(r.Context())
+	}))
+
+	req, _ := http.NewRequest("PATCH", testRoute, strings.NewReader(`[{"op":"replace","path":"/name","value":"  Catalog  "}]`))
+	req.Header.Set("Content-Type", jsonPatchContentType)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(operations) != 1 || string(operations[0].Value) != `"Catalog"` {
+		t.Errorf("Expected the sanitized operation, but got %+v", operations)
+	}
+	if string(patchJson) != `[{"op":"replace","path":"/name","value":"Catalog"}]` {
+		t.Errorf("Expected the operations as the patch json, but got '%s'", patchJson)
+	}
+
+	req, _ = http.NewRequest("PATCH", testRoute, strings.NewReader(`[{"op":"replace","path":"/id","value":2}]`))
+	req.Header.Set("Content-Type", jsonPatchContentType)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != StatusUnprocessableEntity || !strings.Contains(recorder.Body.String(), `"fieldNames":["/id"]`) {
+		t.Errorf("Expected an error on /id, but got %d with body '%s'", recorder.Code, recorder.Body.String())
+	}
+}
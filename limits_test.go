@@ -0,0 +1,65 @@
+package bouncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type (
+	// For exercising the body limits
+	Tree struct {
+		Name     string `json:"name"`
+		Children []Tree `json:"children"`
+	}
+)
+
+func nestedTree(depth int) string {
+	return strings.Repeat(`{"children":[`, depth) + strings.Repeat(`]}`, depth)
+}
+
+func TestBodyLimits(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, testCase := range []struct {
+		description string
+		method      string
+		handler     http.Handler
+		contentType string
+		payload     string
+		status      int
+	}{
+		{"Under the size limit", "POST", NewBouncerHandler(Tree{}, okHandler, MaxBodySize(64)), jsonContentType, `{"name":"oak"}`, http.StatusOK},
+		{"Over the size limit", "POST", NewBouncerHandler(Tree{}, okHandler, MaxBodySize(8)), jsonContentType, `{"name":"oak"}`, http.StatusRequestEntityTooLarge},
+		{"Over the default size limit", "POST", NewBouncerHandler(Tree{}, okHandler), jsonContentType, `{"name":"` + strings.Repeat("a", DefaultMaxBodySize) + `"}`, http.StatusRequestEntityTooLarge},
+		{"Without a size limit", "POST", NewBouncerHandler(Tree{}, okHandler, MaxBodySize(0)), jsonContentType, `{"name":"` + strings.Repeat("a", DefaultMaxBodySize) + `"}`, http.StatusOK},
+		{"Form over the size limit", "POST", NewBouncerHandler(Tree{}, okHandler, MaxBodySize(8)), formContentType, `name=oak&name=elm`, http.StatusRequestEntityTooLarge},
+		{"Xml over the size limit", "POST", NewBouncerHandler(Tree{}, okHandler, MaxBodySize(8)), "application/xml", `<Tree><Name>oak</Name></Tree>`, http.StatusRequestEntityTooLarge},
+		{"Patch over its length", "PATCH", NewBouncerPatchHandler(Tree{}, 8, okHandler), jsonContentType, `{"name":"oak"}`, http.StatusRequestEntityTooLarge},
+		{"Under the depth limit", "POST", NewBouncerHandler(Tree{}, okHandler, MaxDepth(5)), jsonContentType, nestedTree(2), http.StatusOK},
+		{"Over the depth limit", "POST", NewBouncerHandler(Tree{}, okHandler, MaxDepth(4)), jsonContentType, nestedTree(3), http.StatusRequestEntityTooLarge},
+		{"Over the default depth limit", "POST", NewBouncerHandler(Tree{}, okHandler), jsonContentType, nestedTree(DefaultMaxDepth), http.StatusRequestEntityTooLarge},
+		{"Brackets in strings", "POST", NewBouncerHandler(Tree{}, okHandler, MaxDepth(1)), jsonContentType, `{"name":"[[{\"[{"}`, http.StatusOK},
+		{"Patch over the depth limit", "PATCH", NewBouncerPatchHandler(Tree{}, 1024, okHandler, MaxDepth(2)), jsonContentType, nestedTree(2), http.StatusRequestEntityTooLarge},
+		{"JSON Patch over the depth limit", "PATCH", NewBouncerPatchHandler(Tree{}, 1024, okHandler, MaxDepth(2)), jsonPatchContentType, `[{"op":"add","path":"/children/-","value":{"children":[]}}]`, http.StatusRequestEntityTooLarge},
+		{"Under the array limit", "POST", NewBouncerHandler(Tree{}, okHandler, MaxArrayLength(2)), jsonContentType, `{"children":[{},{}]}`, http.StatusOK},
+		{"Over the array limit", "POST", NewBouncerHandler(Tree{}, okHandler, MaxArrayLength(2)), jsonContentType, `{"children":[{},{},{}]}`, http.StatusRequestEntityTooLarge},
+		{"Commas in objects", "POST", NewBouncerHandler(Tree{}, okHandler, MaxArrayLength(1)), jsonContentType, `{"children":[{"name":"a","children":[]}]}`, http.StatusOK},
+	} {
+		req, _ := http.NewRequest(testCase.method, testRoute, strings.NewReader(testCase.payload))
+		req.Header.Set("Content-Type", testCase.contentType)
+		recorder := httptest.NewRecorder()
+		testCase.handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != testCase.status {
+			body := recorder.Body.String()
+			if len(body) > 200 {
+				body = body[:200]
+			}
+			t.Errorf("'%s' should have returned %d, but returned %d with body '%s'", testCase.description, testCase.status, recorder.Code, body)
+		}
+		if testCase.status == http.StatusRequestEntityTooLarge && !strings.Contains(recorder.Body.String(), PayloadTooLargeError) {
+			t.Errorf("'%s' should have returned a PayloadTooLargeError, but returned '%s'", testCase.description, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package bouncer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type (
+	// For exercising the merging of lists
+	Order struct {
+		Lines   []OrderLine `json:"lines" merge:"key=sku"`
+		Notes   []string    `json:"notes"`
+		Shipper Person      `json:"shipper"`
+	}
+
+	OrderLine struct {
+		Sku      string `json:"sku"`
+		Quantity int    `json:"quantity"`
+		Note     string `json:"note"`
+	}
+)
+
+func TestMergeInterfaceLists(t *testing.T) {
+	var dest, src interface{}
+	json.Unmarshal([]byte(`{"notes":["a ", 1],"other":["x"]}`), &dest)
+	json.Unmarshal([]byte(`{"notes":["a", 2],"other":["x", "y"]}`), &src)
+
+	merged, err := MergeInterface(dest, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected interface{}
+	json.Unmarshal([]byte(`{"notes":["a", 2],"other":["x"]}`), &expected)
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected %v, but got %v", expected, merged)
+	}
+}
+
+func TestMergeByKey(t *testing.T) {
+	m := merger{model: reflect.TypeOf(Order{})}
+	finalJson, err := m.createEncodedInterfaceFromOriginal(
+		[]byte(`{"lines":[{"sku":"a","quantity":"1"},{"sku":"b"},{"sku":"c","quantity":3}]}`),
+		[]byte(`{"lines":[{"sku":"c","quantity":4},{"sku":"a","quantity":1}],"notes":null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"lines":[{"quantity":1,"sku":"a"},{"sku":"b"},{"quantity":4,"sku":"c"}]}`
+	if string(finalJson) != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, finalJson)
+	}
+}
+
+func TestPatchSanitizesNestedValues(t *testing.T) {
+	var patchJson []byte
+	handler := NewBouncerPatchHandler(Order{}, 1024, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		patchJson = PatchBody(r.Context())
+	}))
+
+	req, _ := http.NewRequest("PATCH", testRoute, strings.NewReader(`{"shipper":{"name":"  Foo  "},"lines":[{"sku":"a","quantity":1}]}`))
+	req.Header.Set("Content-Type", jsonContentType)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	expected := `{"lines":[{"quantity":1,"sku":"a"}],"shipper":{"name":"Foo"}}`
+	if string(patchJson) != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, patchJson)
+	}
+}
+
+func TestPatchSanitizesItemsWithSanitizedKeys(t *testing.T) {
+	var patchJson []byte
+	handler := NewBouncerPatchHandler(Order{}, 1024, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		patchJson = PatchBody(r.Context())
+	}))
+
+	req, _ := http.NewRequest("PATCH", testRoute, strings.NewReader(`{"lines":[{"sku":" A1 ","note":"  hi  "},{"sku":"B2","note":" there "}]}`))
+	req.Header.Set("Content-Type", jsonContentType)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	expected := `{"lines":[{"note":"hi","sku":"A1"},{"note":"there","sku":"B2"}]}`
+	if string(patchJson) != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, patchJson)
+	}
+}
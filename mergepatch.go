@@ -0,0 +1,120 @@
+package bouncer
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7396), such as the sanitized
+// patch json from NewBouncerPatchHandler, to the current state of a resource
+// and returns the result. Objects in the patch are merged recursively, a null
+// deletes the key, leaving the field at its zero value, and any other value
+// replaces the current one.
+//
+// The patch rules of T are checked again on the result: immutable, required
+// and nonnull fields against the keys in the patch, validate rules against
+// the merged values, and the Validator of T (with method PATCH) against the
+// whole result. current, a struct, map or slice model, is not modified, and
+// if it is a pointer, the result is a pointer to a new copy.
+func ApplyMergePatch[T any](current T, patch []byte) (T, Errors) {
+	return ApplyMergePatchFor(context.Background(), DefaultBouncer, current, patch)
+}
+
+// ApplyMergePatchFor is like ApplyMergePatch, but checks the rules registered
+// on b, passing ctx to them.
+func ApplyMergePatchFor[T any](ctx context.Context, b *Bouncer, current T, patch []byte) (T, Errors) {
+	var errors Errors
+
+	var patchDocument interface{}
+	if err := json.Unmarshal(patch, &patchDocument); err != nil {
+		errors.Add([]string{}, DeserializationError, err.Error())
+		return current, errors
+	}
+	if _, ok := patchDocument.(map[string]interface{}); !ok {
+		errors.Add([]string{}, DeserializationError, "A merge patch must be a json object")
+		return current, errors
+	}
+
+	var currentDocument interface{}
+	currentJson, err := json.Marshal(current)
+	if err == nil {
+		err = json.Unmarshal(currentJson, &currentDocument)
+	}
+	if err != nil {
+		errors.Add([]string{}, DeserializationError, err.Error())
+		return current, errors
+	}
+
+	mergedJson, err := json.Marshal(mergePatch(currentDocument, patchDocument))
+	if err != nil {
+		errors.Add([]string{}, DeserializationError, err.Error())
+		return current, errors
+	}
+
+	// start from a copy of current so fields that don't appear in json are
+	// kept. Maps and slices are decoded from the merged document alone, since
+	// decoding into a map would keep the deleted keys
+	result := reflect.New(modelType(current))
+	if value := indirect(reflect.ValueOf(current)); value.Kind() == reflect.Struct {
+		result.Elem().Set(value)
+		resetJsonFields(result.Elem())
+	}
+	if err = json.Unmarshal(mergedJson, result.Interface()); err != nil {
+		errors.Add([]string{}, DeserializationError, err.Error())
+		return current, errors
+	}
+
+	errors = b.validateModel(ctx, errors, result.Interface(), newPresence(patchDocument), "PATCH", b.options)
+	if len(errors) > 0 {
+		return current, errors
+	}
+	if reflect.TypeOf(current).Kind() == reflect.Ptr {
+		return result.Interface().(T), nil
+	}
+	return result.Elem().Interface().(T), nil
+}
+
+// mergePatch implements the MergePatch function of RFC 7396 on generic json values.
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchMap, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetMap, ok := target.(map[string]interface{})
+	if !ok {
+		targetMap = map[string]interface{}{}
+	}
+
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+		} else {
+			targetMap[key] = mergePatch(targetMap[key], value)
+		}
+	}
+	return targetMap
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// resetJsonFields sets every field that is decoded from json to its zero value,
+// recursing into nested structs, so that unmarshalling the merged document
+// leaves deleted keys at their zero value.
+func resetJsonFields(val reflect.Value) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !val.Field(i).CanSet() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		// structs that decode themselves, like time.Time, are replaced as a whole
+		if field.Type.Kind() == reflect.Struct && !reflect.PtrTo(field.Type).Implements(jsonUnmarshalerType) {
+			resetJsonFields(val.Field(i))
+			continue
+		}
+		val.Field(i).Set(reflect.Zero(field.Type))
+	}
+}
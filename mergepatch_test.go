@@ -0,0 +1,101 @@
+package bouncer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type (
+	// For exercising merge patches
+	Article struct {
+		Id        int64            `json:"id" patch:"-"`
+		Title     string           `json:"title" patch:"required" validate:"min=3"`
+		Body      string           `json:"body"`
+		Subtitle  Nullable[string] `json:"subtitle"`
+		Author    Person           `json:"author"`
+		Published *time.Time       `json:"published"`
+		Revision  int              `json:"-"`
+	}
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	published := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := Article{
+		Id:        1,
+		Title:     "Title",
+		Body:      "Body",
+		Subtitle:  NewNullable("Subtitle"),
+		Author:    Person{Name: "Foo", Email: "foo@example.com"},
+		Published: &published,
+		Revision:  7,
+	}
+
+	result, errs := ApplyMergePatch(current, []byte(`{"title":"New Title","subtitle":null,"author":{"email":null},"published":null}`))
+	if len(errs) > 0 {
+		t.Fatalf("Expected the patch to apply, but got '%+v'", errs)
+	}
+
+	expected := Article{
+		Id:       1,
+		Title:    "New Title",
+		Body:     "Body",
+		Author:   Person{Name: "Foo"},
+		Revision: 7,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, result)
+	}
+	if current.Title != "Title" || current.Author.Email != "foo@example.com" {
+		t.Errorf("Expected current to be left unmodified, but got %+v", current)
+	}
+}
+
+func TestApplyMergePatchRules(t *testing.T) {
+	current := Article{Id: 1, Title: "Title"}
+
+	for _, testCase := range []ruleTestCase{
+		{"Immutable field", `{"id":2}`, ImmutableError},
+		{"Required field deleted", `{"title":null}`, RequiredError},
+		{"Rule on merged value", `{"title":"  a  "}`, MinError},
+		{"Not an object", `["title"]`, DeserializationError},
+		{"Wrong type", `{"title":1}`, DeserializationError},
+	} {
+		result, errs := ApplyMergePatch(current, []byte(testCase.payload))
+		if !errs.Has(testCase.classification) {
+			t.Errorf("'%s' should have failed with %s, but returned '%+v'", testCase.description, testCase.classification, errs)
+		}
+		if !reflect.DeepEqual(result, current) {
+			t.Errorf("'%s' should have returned current unmodified, but returned %+v", testCase.description, result)
+		}
+	}
+}
+
+func TestApplyMergePatchToPointer(t *testing.T) {
+	current := &Article{Id: 1, Title: "Title", Body: "Body"}
+
+	result, errs := ApplyMergePatch(current, []byte(`{"title":"New Title"}`))
+	if len(errs) > 0 {
+		t.Fatalf("Expected the patch to apply, but got '%+v'", errs)
+	}
+	if result == current || result.Title != "New Title" || result.Body != "Body" || current.Title != "Title" {
+		t.Errorf("Expected a patched copy of current, but got %+v from %+v", result, current)
+	}
+}
+
+func TestApplyMergePatchToMap(t *testing.T) {
+	current := map[string]InvoiceLine{"a": {Sku: "A001", Note: "x"}, "b": {Sku: "B001"}}
+
+	result, errs := ApplyMergePatch(current, []byte(`{"a":{"sku":"A002"},"b":null}`))
+	if len(errs) > 0 {
+		t.Fatalf("Expected the patch to apply, but got '%+v'", errs)
+	}
+	expected := map[string]InvoiceLine{"a": {Sku: "A002", Note: "x"}}
+	if !reflect.DeepEqual(result, expected) || len(current) != 2 {
+		t.Errorf("Expected %+v, but got %+v", expected, result)
+	}
+
+	if _, errs = ApplyMergePatch(current, []byte(`{"a":{"sku":null}}`)); !errs.Has(NullError) {
+		t.Errorf("Expected the patch rules of the elements to be checked, but got '%+v'", errs)
+	}
+}
@@ -0,0 +1,77 @@
+package bouncer
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Nullable is a field type that records whether a key was absent, sent as an
+// explicit null, or sent with a value. It is mostly useful for PATCH, where
+// {"nickname": null} means "clear the field" rather than "leave it alone".
+//
+// Validation rules on a Nullable field are checked against Value, and
+// `patch:"nonnull"` rejects nulls like it does for any other field.
+type Nullable[T any] struct {
+	Value T
+
+	// Set is true if the key was present, whether or not it was null
+	Set bool
+
+	// Null is true if the key was present with an explicit null
+	Null bool
+}
+
+// NewNullable returns a Nullable holding value.
+func NewNullable[T any](value T) Nullable[T] {
+	return Nullable[T]{Value: value, Set: true}
+}
+
+// Null returns a Nullable holding an explicit null.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{Set: true, Null: true}
+}
+
+// Get returns the value and whether there is one, i.e. it was set and not null.
+func (n Nullable[T]) Get() (T, bool) {
+	return n.Value, n.Set && !n.Null
+}
+
+// UnmarshalJSON is only called when the key is present, so it always marks n as set.
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero T
+		n.Value = zero
+		n.Null = true
+		return nil
+	}
+	n.Null = false
+	return json.Unmarshal(data, &n.Value)
+}
+
+// MarshalJSON writes null for both absent and null values.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.Set || n.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Value)
+}
+
+func (n Nullable[T]) nullable() {}
+
+// nullableField is implemented by every Nullable[T], so they can be told
+// apart from other structs when walking a model.
+type nullableField interface {
+	nullable()
+}
+
+var nullableFieldType = reflect.TypeOf((*nullableField)(nil)).Elem()
+
+// unwrapNullable returns the Value of a Nullable field, or the field itself.
+func unwrapNullable(value reflect.Value) reflect.Value {
+	if value.Type().Implements(nullableFieldType) && value.Kind() == reflect.Struct {
+		return value.FieldByName("Value")
+	}
+	return value
+}
@@ -0,0 +1,76 @@
+package bouncer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// For exercising nullable fields in patches
+type Profile struct {
+	Nickname string             `json:"nickname"`
+	Bio      Nullable[string]   `json:"bio" validate:"max=10"`
+	Email    Nullable[string]   `json:"email" patch:"nonnull"`
+	Age      Nullable[int]      `json:"age"`
+	Tags     Nullable[[]string] `json:"tags"`
+}
+
+func TestNullableUnmarshal(t *testing.T) {
+	var profile Profile
+	if err := json.Unmarshal([]byte(`{"bio":null,"age":3}`), &profile); err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.Email.Set || profile.Email.Null {
+		t.Errorf("Expected an absent key to be unset, but got %+v", profile.Email)
+	}
+	if !profile.Bio.Set || !profile.Bio.Null {
+		t.Errorf("Expected an explicit null to be set and null, but got %+v", profile.Bio)
+	}
+	if age, ok := profile.Age.Get(); !ok || age != 3 {
+		t.Errorf("Expected a value of 3, but got %+v", profile.Age)
+	}
+}
+
+func TestNonNull(t *testing.T) {
+	_, errs := ValidateJson(Profile{}, []byte(`{"bio":null,"email":"foo@example.com"}`), "PATCH")
+	if len(errs) > 0 {
+		t.Errorf("Expected nulls to be accepted, but got '%+v'", errs)
+	}
+
+	_, errs = ValidateJson(Profile{}, []byte(`{"email":null}`), "PATCH")
+	if len(errs) != 1 || !errs.Has(NullError) {
+		t.Errorf("Expected a NullError, but got '%+v'", errs)
+	}
+
+	_, errs = ValidateJson(Profile{}, []byte(`{"bio":"   a very long bio   "}`), "PATCH")
+	if len(errs) != 1 || !errs.Has(MaxError) {
+		t.Errorf("Expected a MaxError from the rules on the nullable value, but got '%+v'", errs)
+	}
+}
+
+func TestNullablePatch(t *testing.T) {
+	payload := `{"nickname":null,"bio":null,"age":null,"tags":["a"]}`
+	for _, testCase := range []struct {
+		opts     []Option
+		expected string
+	}{
+		{nil, `{"age":null,"bio":null,"nickname":"","tags":["a"]}`},
+		{[]Option{NullablePatch()}, `{"age":null,"bio":null,"nickname":null,"tags":["a"]}`},
+	} {
+		var patchJson []byte
+		handler := NewBouncerPatchHandler(Profile{}, 1024, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			patchJson = PatchBody(r.Context())
+		}), testCase.opts...)
+
+		req, _ := http.NewRequest("PATCH", testRoute, strings.NewReader(payload))
+		req.Header.Set("Content-Type", jsonContentType)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if string(patchJson) != testCase.expected {
+			t.Errorf("Expected the patch json '%s', but got '%s'", testCase.expected, patchJson)
+		}
+	}
+}
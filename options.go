@@ -0,0 +1,128 @@
+package bouncer
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Option configures how a handler validates requests. Options passed to New
+// apply to every handler created from that Bouncer, and options passed to a
+// handler constructor apply to that handler only.
+type Option func(*options)
+
+type options struct {
+	nullablePatch bool
+	mediaTypes    []string
+	strict        bool
+	pathNotation  PathNotation
+	pathParams    func(*http.Request) map[string]string
+
+	maxBodySize    int64
+	maxDepth       int
+	maxArrayLength int
+
+	// profiles maps methods to the profile their bodies are validated
+	// against, unless profile is set, see MethodProfile
+	profiles map[string]string
+	profile  string
+}
+
+const (
+	// DefaultMaxBodySize is the largest body handlers read, unless changed with MaxBodySize.
+	DefaultMaxBodySize = 1 << 20
+
+	// DefaultMaxDepth is how deeply json bodies may nest objects and arrays,
+	// unless changed with MaxDepth.
+	DefaultMaxDepth = 32
+)
+
+// defaultOptions are the options of a Bouncer before its own are applied.
+func defaultOptions() options {
+	return options{
+		maxBodySize: DefaultMaxBodySize,
+		maxDepth:    DefaultMaxDepth,
+		profiles:    defaultProfiles,
+	}
+}
+
+// NullablePatch makes patch handlers keep explicit nulls in the sanitized
+// patch json, so that {"nickname": null} can be told apart from an omitted
+// key and used to clear the field. Without it, nulls are replaced by the zero
+// value of the field. Use `patch:"nonnull"` to reject nulls on specific fields.
+func NullablePatch() Option {
+	return func(o *options) {
+		o.nullablePatch = true
+	}
+}
+
+// Strict makes handlers reject json bodies with keys that don't decode into a
+// field of the model, reporting each of them as an UnknownFieldError named by
+// its full path, e.g. "address.zip" or "lines[0].sku", and bodies with data
+// after the json value. A model can opt in to strict decoding on its own with
+// a blank field tagged `strict:"true"`, which also applies to the structs
+// nested in it.
+func Strict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// MediaTypes restricts the media types a handler accepts, e.g.
+// MediaTypes("application/json"). Requests with any other Content-Type are
+// rejected with a ContentTypeError, which ErrorHandler reports as a 415.
+// Without it, handlers accept every media type they can decode. Requests
+// without a Content-Type are treated as application/json.
+func MediaTypes(mediaTypes ...string) Option {
+	return func(o *options) {
+		o.mediaTypes = make([]string, len(mediaTypes))
+		for i, mediaType := range mediaTypes {
+			o.mediaTypes[i] = strings.ToLower(strings.TrimSpace(mediaType))
+		}
+	}
+}
+
+// MaxBodySize limits how many bytes of the body handlers read. Larger bodies
+// are rejected with a PayloadTooLargeError, which ErrorHandler reports as a
+// 413. Zero or less removes the limit. Patch handlers use their own
+// maxBodyLength instead.
+func MaxBodySize(n int64) Option {
+	return func(o *options) {
+		o.maxBodySize = n
+	}
+}
+
+// MaxDepth limits how deeply objects and arrays may be nested in json bodies.
+// Deeper bodies are rejected with a PayloadTooLargeError. Zero or less
+// removes the limit.
+func MaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// MaxArrayLength limits the number of items of every array in json bodies.
+// Bodies with longer arrays are rejected with a PayloadTooLargeError. There
+// is no limit by default, other than the size of the body.
+func MaxArrayLength(n int) Option {
+	return func(o *options) {
+		o.maxArrayLength = n
+	}
+}
+
+// PathParams gives query handlers the path parameters of a request, for
+// fields tagged `path:"id"`. Pass the accessor of your router, e.g.
+// PathParams(mux.Vars) for gorilla/mux, or a func that adapts it.
+func PathParams(params func(r *http.Request) map[string]string) Option {
+	return func(o *options) {
+		o.pathParams = params
+	}
+}
+
+// handlerOptions applies the options for a single handler on top of b's.
+func (b *Bouncer) handlerOptions(opts []Option) options {
+	o := b.options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,68 @@
+package bouncer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PathNotation is how errors name fields nested in a body, see ErrorPaths.
+type PathNotation int
+
+const (
+	// DotNotation names nested fields like owner.name and items[3].sku.
+	DotNotation PathNotation = iota
+
+	// PointerNotation names fields with JSON Pointers (RFC 6901), like
+	// /owner/name and /items/3/sku.
+	PointerNotation
+)
+
+// ErrorPaths chooses the notation of the FieldNames of errors on fields in a
+// body, which hold the full path to the field. The default is DotNotation.
+// Errors on query parameters and headers are named by the parameter or
+// header, whatever the notation.
+func ErrorPaths(notation PathNotation) Option {
+	return func(o *options) {
+		o.pathNotation = notation
+	}
+}
+
+// fieldPath is the path to a value in a request body, as reported in errors.
+type fieldPath struct {
+	notation PathNotation
+	path     string
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// rootPath is the path to the body itself in the notation of o.
+func rootPath(o options) fieldPath {
+	return fieldPath{notation: o.pathNotation}
+}
+
+// key returns the path to the member name of the object at p.
+func (p fieldPath) key(name string) fieldPath {
+	switch {
+	case p.notation == PointerNotation:
+		p.path += "/" + pointerEscaper.Replace(name)
+	case p.path == "":
+		p.path = name
+	default:
+		p.path += "." + name
+	}
+	return p
+}
+
+// index returns the path to item i of the list at p.
+func (p fieldPath) index(i int) fieldPath {
+	if p.notation == PointerNotation {
+		p.path += "/" + strconv.Itoa(i)
+	} else {
+		p.path += "[" + strconv.Itoa(i) + "]"
+	}
+	return p
+}
+
+func (p fieldPath) String() string {
+	return p.path
+}
@@ -0,0 +1,66 @@
+package bouncer
+
+import (
+	"context"
+	"testing"
+)
+
+type (
+	// For exercising the paths of errors on nested fields
+	Team struct {
+		Name   string   `json:"name" create:"required"`
+		Owner  Person   `json:"owner" create:"required"`
+		Coach  *Person  `json:"coach"`
+		Badge  Badge    `json:"badge"`
+		Scores []int8   `json:"scores"`
+		Tags   []string `json:"tags,omitempty" validate:"max=2"`
+		Audit
+	}
+
+	Badge struct {
+		Color string `json:"color" validate:"oneof=red blue"`
+	}
+)
+
+func TestErrorPaths(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		opts        []Option
+		payload     string
+		paths       []string
+	}{
+		{"Nested required field", nil, `{"name":"A","owner":{}}`, []string{"owner.name"}},
+		{"Nested pointer", nil, `{"name":"A","owner":{"name":"B"},"coach":{"email":"c"}}`, []string{"coach.name"}},
+		{"Nested rule", nil, `{"name":"A","owner":{"name":"B"},"badge":{"color":"green"}}`, []string{"badge.color"}},
+		{"Type error in a list", nil, `{"name":"A","owner":{"name":"B"},"scores":[1,1000]}`, []string{"scores[1]"}},
+		{"Json options", nil, `{"name":"A","owner":{"name":"B"},"tags":["a","b","c"]}`, []string{"tags"}},
+		{"Top level", nil, `{"owner":{"name":"B"}}`, []string{"name"}},
+		{"Pointer nested required field", []Option{ErrorPaths(PointerNotation)}, `{"name":"A","owner":{}}`, []string{"/owner/name"}},
+		{"Pointer type error in a list", []Option{ErrorPaths(PointerNotation)}, `{"name":"A","owner":{"name":"B"},"scores":[1,1000]}`, []string{"/scores/1"}},
+		{"Pointer unknown field", []Option{ErrorPaths(PointerNotation), Strict()}, `{"name":"A","owner":{"name":"B","a/b":1}}`, []string{"/owner/a~1b"}},
+		{"Pointer top level", []Option{ErrorPaths(PointerNotation)}, `{"owner":{"name":"B"}}`, []string{"/name"}},
+	} {
+		_, errs := New(testCase.opts...).ValidateJson(Team{}, []byte(testCase.payload), "POST")
+		if len(errs) != len(testCase.paths) {
+			t.Errorf("'%s' should have returned %d errors, but returned '%+v'", testCase.description, len(testCase.paths), errs)
+			continue
+		}
+		for i, path := range testCase.paths {
+			if len(errs[i].FieldNames) != 1 || errs[i].FieldNames[0] != path {
+				t.Errorf("'%s' should have returned an error on %s, but returned '%+v'", testCase.description, path, errs[i])
+			}
+		}
+	}
+}
+
+func TestErrorPathsInJsonPatch(t *testing.T) {
+	_, errs := New().validateJsonPatch(context.Background(), Team{}, "patch", []byte(`[{"op":"add","path":"/coach","value":{"email":"c"}}]`))
+	if len(errs) != 0 {
+		t.Fatalf("Expected nested required fields to be ignored in a patch, but got '%+v'", errs)
+	}
+
+	_, errs = New().validateJsonPatch(context.Background(), Team{}, "patch", []byte(`[{"op":"add","path":"/badge","value":{"color":"green"}}]`))
+	if len(errs) != 1 || errs[0].FieldNames[0] != "/badge/color" {
+		t.Errorf("Expected an error on /badge/color, but got '%+v'", errs)
+	}
+}
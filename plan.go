@@ -0,0 +1,194 @@
+package bouncer
+
+import (
+	"reflect"
+	"strings"
+)
+
+// structPlan is how the fields of a struct type are validated, worked out
+// once from their tags so that requests don't have to walk them again.
+type structPlan struct {
+	fields []fieldPlan
+}
+
+// fieldPlan is a field of a struct, see jsonFields, with its tags parsed.
+type fieldPlan struct {
+	jsonField
+
+	// errorName names the field in errors, see fieldName
+	errorName string
+
+	// header is the header the field is bound from, if any, and headerRequired
+	// is set by `header:"...,required"`
+	header         string
+	headerRequired bool
+
+	// skipped is set for fields tagged `form:"-"`, which are never validated
+	skipped bool
+
+	// notrim is set for fields whose strings aren't trimmed
+	notrim bool
+
+	// nullable is set for Nullable fields, which are validated by their Value
+	nullable bool
+
+	// nested is set for structs and pointers to structs, which are validated
+	// field by field
+	nested bool
+
+	// elements is set for slices, arrays and maps holding structs
+	elements bool
+
+	// modes holds the required, immutable and nonnull rules of the field in
+	// the tags named by planTagKeys
+	modes map[string]fieldMode
+
+	// rules are the rules of the validate tag, in order
+	rules []planRule
+}
+
+// fieldMode is what the create, patch or query tag of a field asks for.
+type fieldMode struct {
+	required  bool
+	immutable bool
+	nonnull   bool
+}
+
+// planRule is a rule of a validate tag, looked up once. Unknown rules only
+// panic when they are checked.
+type planRule struct {
+	rule
+	name  string
+	param string
+	known bool
+
+	// dive is set for the dive pseudo rule, see diveRules
+	dive bool
+}
+
+// the tags of the modes that are parsed when a plan is compiled
+var planTagKeys = []string{"create", "patch", "query"}
+
+// plan returns the plan of a struct type, compiling it the first time the
+// type is seen. Plans are cached by b, since they hold its rules, and are
+// dropped when a rule is registered.
+func (b *Bouncer) plan(typ reflect.Type) *structPlan {
+	if p, ok := b.plans.Load(typ); ok {
+		return p.(*structPlan)
+	}
+	p, _ := b.plans.LoadOrStore(typ, b.compilePlan(typ))
+	return p.(*structPlan)
+}
+
+// dropPlans forgets the plans compiled so far, so that each type is compiled
+// again on its next use.
+func (b *Bouncer) dropPlans() {
+	b.plans.Range(func(typ, _ interface{}) bool {
+		b.plans.Delete(typ)
+		return true
+	})
+}
+
+func (b *Bouncer) compilePlan(typ reflect.Type) *structPlan {
+	fields := jsonFields(typ)
+	plan := &structPlan{fields: make([]fieldPlan, len(fields))}
+
+	for i, f := range fields {
+		field := f.field
+		actual := field.Type
+		nullable := actual.Kind() == reflect.Struct && actual.Implements(nullableFieldType)
+		if nullable {
+			actual = actual.Field(0).Type
+		}
+		list := indirectType(actual).Kind()
+
+		p := fieldPlan{
+			jsonField: f,
+			errorName: fieldName(field),
+			skipped:   field.Tag.Get("form") == "-",
+			notrim:    field.Tag.Get("notrim") == "true",
+			nullable:  nullable,
+			nested: actual.Kind() == reflect.Struct ||
+				(actual.Kind() == reflect.Ptr && actual.Elem().Kind() == reflect.Struct),
+			elements: (list == reflect.Slice || list == reflect.Array || list == reflect.Map) &&
+				holdsStructs(indirectType(actual).Elem()),
+			modes: make(map[string]fieldMode, len(planTagKeys)),
+		}
+		p.header, p.headerRequired = headerName(field)
+		for _, key := range planTagKeys {
+			p.modes[key] = parseMode(key, field.Tag.Get(key))
+		}
+		for _, r := range splitRules(field.Tag.Get("validate")) {
+			p.rules = append(p.rules, b.compileRule(r))
+		}
+		plan.fields[i] = p
+	}
+	return plan
+}
+
+// compileRule looks up a rule of a validate tag, e.g. "max=5".
+func (b *Bouncer) compileRule(r string) planRule {
+	if r == "dive" {
+		return planRule{name: r, known: true, dive: true}
+	}
+
+	name, param := r, ""
+	if i := strings.Index(r, "="); i > -1 {
+		name, param = r[:i], r[i+1:]
+	}
+	rule, known := b.lookupRule(name)
+	return planRule{rule: rule, name: name, param: param, known: known}
+}
+
+// parseMode parses the tag named key: a profile, such as create or patch, or
+// query, whose options follow the name of the parameter.
+func parseMode(key string, tag string) fieldMode {
+	mode := fieldMode{immutable: tag == "-"}
+	options := strings.Split(tag, ",")
+	if key == "query" {
+		options = options[1:]
+	}
+	for _, option := range options {
+		switch strings.TrimSpace(option) {
+		case "required":
+			mode.required = true
+		case "nonnull":
+			mode.nonnull = true
+		}
+	}
+	return mode
+}
+
+// mode returns what the tag named tagKey asks of the field.
+func (f *fieldPlan) mode(tagKey string) fieldMode {
+	if m, ok := f.modes[tagKey]; ok {
+		return m
+	}
+	return parseMode(tagKey, f.field.Tag.Get(tagKey))
+}
+
+// actual returns the value of the field in v that is validated: the Value of
+// a Nullable, or the field itself.
+func (f *fieldPlan) actual(v reflect.Value) reflect.Value {
+	if f.nullable {
+		return v.Field(0)
+	}
+	return v
+}
+
+// lookup finds the field decoded from the given key, see jsonFieldByName.
+func (p *structPlan) lookup(name string) (*fieldPlan, bool) {
+	var match *fieldPlan
+	for i := range p.fields {
+		if p.fields[i].ignored {
+			continue
+		}
+		if p.fields[i].name == name {
+			return &p.fields[i], true
+		}
+		if match == nil && strings.EqualFold(p.fields[i].name, name) {
+			match = &p.fields[i]
+		}
+	}
+	return match, match != nil
+}
@@ -0,0 +1,115 @@
+package bouncer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type (
+	// For measuring the cost of validating a request
+	Shop struct {
+		Name    string            `json:"name" create:"required" validate:"min=2,max=40"`
+		Email   string            `json:"email" create:"required" validate:"email"`
+		Website string            `json:"website" validate:"url"`
+		Owner   Person            `json:"owner" create:"required"`
+		Lines   []InvoiceLine     `json:"lines" validate:"min=1,max=50"`
+		Tags    []string          `json:"tags" validate:"max=5,dive,min=2"`
+		Hours   map[string]string `json:"hours" validate:"dive,len=11"`
+		Opened  int               `json:"opened" validate:"gte=1900,lte=2100"`
+		Audit
+	}
+)
+
+const shopPayload = `{"name":" Corner Shop ","email":"shop@example.com","website":"https://example.com",
+	"owner":{"name":"Ann","email":"ann@example.com"},"lines":[{"sku":"A001"},{"sku":"B002","note":"x"}],
+	"tags":["food","local"],"hours":{"mon":"09:00-17:00"},"opened":1999,"created_by":"ann"}`
+
+func TestPlanIsCached(t *testing.T) {
+	b := New()
+	typ := reflect.TypeOf(Shop{})
+	if b.plan(typ) != b.plan(typ) {
+		t.Errorf("Expected the plan of a type to be compiled once")
+	}
+	if New().plan(typ) == b.plan(typ) {
+		t.Errorf("Expected each Bouncer to compile its own plans")
+	}
+}
+
+func TestRegisterRuleDropsPlans(t *testing.T) {
+	type Coupon struct {
+		Code string `json:"code" validate:"coupon"`
+	}
+
+	b := New()
+	b.RegisterRule("coupon", func(ctx context.Context, value interface{}, param string) error { return nil })
+	if _, errs := b.ValidateJson(Coupon{}, []byte(`{"code":"X"}`), "POST"); len(errs) > 0 {
+		t.Fatalf("Expected the coupon to be valid, but got '%+v'", errs)
+	}
+
+	b.RegisterRule("coupon", func(ctx context.Context, value interface{}, param string) error {
+		return Error{Classification: "ExpiredError", Message: "Expired"}
+	})
+	if _, errs := b.ValidateJson(Coupon{}, []byte(`{"code":"X"}`), "POST"); !errs.Has("ExpiredError") {
+		t.Errorf("Expected the rule registered last to be used, but got '%+v'", errs)
+	}
+}
+
+// BenchmarkHandler and BenchmarkValidateStruct use the cached plans, and their
+// Uncached variants compile them again for every request, as was done before
+// plans were cached, to compare the two.
+func BenchmarkHandler(b *testing.B) {
+	benchmarkHandler(b, false)
+}
+
+func BenchmarkHandlerUncached(b *testing.B) {
+	benchmarkHandler(b, true)
+}
+
+func benchmarkHandler(b *testing.B, uncached bool) {
+	handler := NewBouncerHandler(Shop{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if uncached {
+				DefaultBouncer.dropPlans()
+			}
+			req := httptest.NewRequest("POST", testRoute, strings.NewReader(shopPayload))
+			req.Header.Set("Content-Type", jsonContentType)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			if recorder.Code != http.StatusOK {
+				b.Fatalf("Expected the shop to be valid, but got %d: %s", recorder.Code, recorder.Body.String())
+			}
+		}
+	})
+}
+
+func BenchmarkValidateStruct(b *testing.B) {
+	benchmarkValidateStruct(b, false)
+}
+
+func BenchmarkValidateStructUncached(b *testing.B) {
+	benchmarkValidateStruct(b, true)
+}
+
+func benchmarkValidateStruct(b *testing.B, uncached bool) {
+	shop, errs := DefaultBouncer.ValidateJson(Shop{}, []byte(shopPayload), "POST")
+	if len(errs) > 0 {
+		b.Fatalf("Expected the shop to be valid, but got '%+v'", errs)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if uncached {
+			DefaultBouncer.dropPlans()
+		}
+		DefaultBouncer.validateStruct(context.Background(), nil, "create", shop, nil, fieldPath{})
+	}
+}
@@ -0,0 +1,200 @@
+package bouncer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// presence records which keys were sent in a request body, so that a field
+// that is absent can be told apart from one sent with its zero value.
+type presence struct {
+	// keys holds the presence of each key of an object
+	keys map[string]*presence
+
+	// items holds the presence of each item of a list
+	items []*presence
+
+	// bound holds the presence of the fields tagged `json:"-"` that were
+	// bound from somewhere else, like a form, keyed by go field name, so
+	// that they can't be mistaken for a "-" key
+	bound map[string]*presence
+
+	// null is set when the value was an explicit null
+	null bool
+
+	// invalid is set when the value couldn't be decoded into its field,
+	// which has already been reported, so the field's rules are skipped
+	invalid bool
+}
+
+// presenceFromJson builds the presence of every key in a json document.
+func presenceFromJson(jsonData []byte) *presence {
+	var document interface{}
+	if err := json.Unmarshal(jsonData, &document); err != nil {
+		return &presence{}
+	}
+	return newPresence(document)
+}
+
+func newPresence(document interface{}) *presence {
+	p := &presence{}
+	switch v := document.(type) {
+	case nil:
+		p.null = true
+	case map[string]interface{}:
+		p.keys = make(map[string]*presence, len(v))
+		for key, value := range v {
+			p.keys[key] = newPresence(value)
+		}
+	case []interface{}:
+		p.items = make([]*presence, len(v))
+		for i, value := range v {
+			p.items[i] = newPresence(value)
+		}
+	}
+	return p
+}
+
+// field looks up the presence of the key name, matching it the same way
+// encoding/json does: exactly, or failing that case-insensitively.
+// If p is nil the presence isn't known, and the field is reported as
+// present when value is not its zero value.
+func (p *presence) field(name string, value reflect.Value) (*presence, bool) {
+	if p == nil {
+		return nil, !isZero(value)
+	}
+
+	if child, ok := p.keys[name]; ok {
+		return child, true
+	}
+	for key, child := range p.keys {
+		if strings.EqualFold(key, name) {
+			return child, true
+		}
+	}
+
+	// report an empty presence so nested structs are validated as absent
+	return &presence{}, false
+}
+
+// structField looks up the presence of a field of a struct, see field.
+// Fields tagged `json:"-"` are never looked up among the keys of a json
+// document, only among the fields bound from elsewhere.
+func (p *presence) structField(f jsonField, value reflect.Value) (*presence, bool) {
+	if p == nil || !f.ignored {
+		return p.field(f.name, value)
+	}
+	if child, ok := p.bound[f.field.Name]; ok {
+		return child, true
+	}
+	return &presence{}, false
+}
+
+// setField records the presence of a field of a struct bound from a form or
+// query string.
+func (p *presence) setField(f jsonField, child *presence) {
+	if !f.ignored {
+		p.keys[f.name] = child
+		return
+	}
+	if p.bound == nil {
+		p.bound = map[string]*presence{}
+	}
+	p.bound[f.field.Name] = child
+}
+
+// child returns the presence of the key name of an object, or nil if it
+// isn't known.
+func (p *presence) child(name string) *presence {
+	if p == nil {
+		return nil
+	}
+	return p.keys[name]
+}
+
+// item returns the presence of the item at index i of a list, or nil if it
+// isn't known.
+func (p *presence) item(i int) *presence {
+	if p == nil || i >= len(p.items) {
+		return nil
+	}
+	return p.items[i]
+}
+
+// isValid reports whether the value was decoded into its field.
+func (p *presence) isValid() bool {
+	return p == nil || !p.invalid
+}
+
+// isNull reports whether the value was sent as an explicit null.
+func (p *presence) isNull() bool {
+	return p != nil && p.null
+}
+
+// filterJson removes the keys that are not present from a json document.
+func (p *presence) filterJson(jsonData []byte) ([]byte, error) {
+	var document interface{}
+	if err := json.Unmarshal(jsonData, &document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(p.filter(document))
+}
+
+// Values without presence information for their keys or items, like those
+// bound from a single form value, are kept as a whole.
+//
+// Without presence information at all, keys are kept when their value is not
+// the zero value, like when validating.
+func (p *presence) filter(document interface{}) interface{} {
+	switch v := document.(type) {
+	case map[string]interface{}:
+		if p == nil {
+			filtered := map[string]interface{}{}
+			for key, value := range v {
+				if value = p.filter(value); !isZeroJson(value) {
+					filtered[key] = value
+				}
+			}
+			return filtered
+		}
+		if p.keys == nil {
+			return v
+		}
+		filtered := map[string]interface{}{}
+		for key, value := range v {
+			if child, ok := p.field(key, reflect.Value{}); ok {
+				filtered[key] = child.filter(value)
+			}
+		}
+		return filtered
+	case []interface{}:
+		if p == nil || p.items == nil || len(p.items) != len(v) {
+			return v
+		}
+		for i, value := range v {
+			v[i] = p.items[i].filter(value)
+		}
+		return v
+	}
+	return document
+}
+
+// isZeroJson reports whether a generic json value is the encoding of a zero value.
+func isZeroJson(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
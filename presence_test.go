@@ -0,0 +1,53 @@
+package bouncer
+
+import (
+	"testing"
+)
+
+// For exercising required fields with zero values
+type Toggle struct {
+	Id      int64  `json:"id" create:"-"`
+	Count   int    `json:"count" create:"required"`
+	Label   string `json:"label" create:"required" validate:"max=5"`
+	Active  bool   `json:"active" create:"required"`
+	Owner   Person `json:"owner"`
+	Comment *string
+}
+
+var presenceTestCases = []ruleTestCase{
+	{"Zero values satisfy required", `{"count":0,"label":"","active":false,"owner":{"name":""}}`, ""},
+	{"Keys are matched case-insensitively", `{"COUNT":0,"Label":"","active":false,"owner":{"Name":"Foo"}}`, ""},
+	{"Missing key is required", `{"count":0,"label":"","owner":{"name":""}}`, RequiredError},
+	{"Null is required", `{"count":0,"label":"","active":null,"owner":{"name":""}}`, RequiredError},
+	{"Missing nested key is required", `{"count":0,"label":"","active":false,"owner":{}}`, RequiredError},
+	{"Immutable zero value", `{"id":0,"count":0,"label":"","active":false,"owner":{"name":""}}`, ""},
+	{"Immutable value", `{"id":1,"count":0,"label":"","active":false,"owner":{"name":""}}`, ImmutableError},
+	{"Rules apply to zero values that were sent", `{"count":0,"label":"     ","active":false,"owner":{"name":""},"comment":"x"}`, ""},
+	{"Rules apply to sent values", `{"count":0,"label":"foo bar","active":false,"owner":{"name":""}}`, MaxError},
+}
+
+func TestPresence(t *testing.T) {
+	for _, testCase := range presenceTestCases {
+		_, errs := ValidateJson(Toggle{}, []byte(testCase.payload), "POST")
+		if testCase.classification == "" && len(errs) > 0 {
+			t.Errorf("'%s' should have succeeded, but returned errors '%+v'", testCase.description, errs)
+		} else if testCase.classification != "" && (len(errs) != 1 || !errs.Has(testCase.classification)) {
+			t.Errorf("'%s' should have failed with a single %s, but returned '%+v'",
+				testCase.description, testCase.classification, errs)
+		}
+	}
+}
+
+// For exercising fields that aren't decoded from json
+type Hidden struct {
+	Id     int64  `json:"-" create:"-"`
+	Secret string `json:"-" create:"required"`
+	Name   string `json:"name"`
+}
+
+func TestIgnoredFieldsAreNeverSent(t *testing.T) {
+	_, errs := ValidateJson(Hidden{}, []byte(`{"-":5,"name":"x"}`), "POST")
+	if len(errs) != 1 || !errs.Has(RequiredError) || errs.Has(ImmutableError) {
+		t.Errorf(`Expected a "-" key to leave the fields tagged json:"-" unsent, but got '%+v'`, errs)
+	}
+}
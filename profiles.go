@@ -0,0 +1,83 @@
+package bouncer
+
+import (
+	"sort"
+	"strings"
+)
+
+// the profiles of the methods that validate bodies, unless changed with MethodProfile
+var defaultProfiles = map[string]string{
+	"POST":  "create",
+	"PUT":   "create",
+	"PATCH": "patch",
+}
+
+// MethodProfile validates the bodies of requests with the given method
+// against a profile: the tag named profile, which marks fields as required,
+// immutable ("-") or nonnull like the create and patch tags do, along with
+// the validate tags of the fields that were sent. By default, POST and PUT
+// use create and PATCH uses patch, and the fields of other methods' bodies
+// aren't validated. For example, MethodProfile("PUT", "replace") lets a full
+// replacement have its own rules:
+//
+//	type Product struct {
+//		Id  string `json:"id" create:"-" replace:"required" patch:"-"`
+//		Sku string `json:"sku" create:"required" replace:"required"`
+//	}
+//
+// A profile that no field is tagged with only checks the validate tags, and
+// an empty profile turns off the validation of fields for the method.
+func MethodProfile(method string, profile string) Option {
+	return func(o *options) {
+		// the map may be shared with the Bouncer the options came from
+		current := o.profiles
+		if current == nil {
+			current = defaultProfiles
+		}
+		profiles := make(map[string]string, len(current)+1)
+		for m, p := range current {
+			profiles[m] = p
+		}
+		profiles[strings.ToUpper(method)] = profile
+		o.profiles = profiles
+	}
+}
+
+// ValidationProfile validates the bodies of all requests against the given profile,
+// whatever their method, see MethodProfile. It is mostly useful as an option
+// of a single handler, e.g. one that takes PUT or POST for an upsert.
+func ValidationProfile(profile string) Option {
+	return func(o *options) {
+		o.profile = profile
+	}
+}
+
+// profileFor returns the profile that bodies of requests with the given
+// method are validated against, if any.
+func (o options) profileFor(method string) string {
+	if o.profile != "" {
+		return o.profile
+	}
+	if o.profiles == nil {
+		return defaultProfiles[method]
+	}
+	return o.profiles[method]
+}
+
+// profileKeys returns the names of the tags used as profiles by o, along with
+// create and patch, in order.
+func (o options) profileKeys() []string {
+	seen := map[string]bool{"": true, "create": true, "patch": true}
+	var custom []string
+	for _, profile := range o.profiles {
+		if !seen[profile] {
+			seen[profile] = true
+			custom = append(custom, profile)
+		}
+	}
+	if !seen[o.profile] {
+		custom = append(custom, o.profile)
+	}
+	sort.Strings(custom)
+	return append([]string{"create", "patch"}, custom...)
+}
@@ -0,0 +1,87 @@
+package bouncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type (
+	// For exercising validation profiles
+	Listing struct {
+		Id    string `json:"id" create:"-" replace:"required" patch:"-"`
+		Sku   string `json:"sku" create:"required" replace:"required" edit:"-"`
+		Title string `json:"title" replace:"nonnull" validate:"min=2"`
+	}
+
+	MisspelledListing struct {
+		Id string `json:"id" replace:"requierd"`
+	}
+)
+
+func TestProfiles(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, testCase := range []struct {
+		description    string
+		opts           []Option
+		method         string
+		contentType    string
+		payload        string
+		classification string
+	}{
+		{"PUT uses create", nil, "PUT", jsonContentType, `{"id":"1","sku":"A"}`, ImmutableError},
+		{"PUT uses replace", []Option{MethodProfile("PUT", "replace")}, "PUT", jsonContentType, `{"id":"1","sku":"A"}`, ""},
+		{"Replace requires its own fields", []Option{MethodProfile("put", "replace")}, "PUT", jsonContentType, `{"sku":"A"}`, RequiredError},
+		{"Replace rejects nulls", []Option{MethodProfile("PUT", "replace")}, "PUT", jsonContentType, `{"id":"1","sku":"A","title":null}`, NullError},
+		{"POST keeps create", []Option{MethodProfile("PUT", "replace")}, "POST", jsonContentType, `{"id":"1","sku":"A"}`, ImmutableError},
+		{"Profile of a handler", []Option{ValidationProfile("replace")}, "POST", jsonContentType, `{"sku":"A"}`, RequiredError},
+		{"Rules of a profile", []Option{ValidationProfile("replace")}, "POST", jsonContentType, `{"id":"1","sku":"A","title":"x"}`, MinError},
+		{"Profile without tags", []Option{MethodProfile("POST", "upsert")}, "POST", jsonContentType, `{"id":"1","title":"x"}`, MinError},
+		{"No profile", []Option{MethodProfile("POST", "")}, "POST", jsonContentType, `{"id":"1","title":"x"}`, ""},
+		{"Custom verb", []Option{MethodProfile("PURGE", "replace")}, "PURGE", jsonContentType, `{"sku":"A"}`, RequiredError},
+		{"JSON Patch uses its profile", []Option{MethodProfile("PATCH", "edit")}, "PATCH", jsonPatchContentType, `[{"op":"replace","path":"/sku","value":"B"}]`, ImmutableError},
+		{"JSON Patch leaves other profiles", []Option{MethodProfile("PATCH", "edit")}, "PATCH", jsonPatchContentType, `[{"op":"replace","path":"/id","value":"2"}]`, ""},
+	} {
+		var handler http.Handler
+		if testCase.method == "PATCH" {
+			handler = NewBouncerPatchHandler(Listing{}, 1024, okHandler, testCase.opts...)
+		} else {
+			handler = NewBouncerHandler(Listing{}, okHandler, testCase.opts...)
+		}
+
+		req := httptest.NewRequest(testCase.method, testRoute, strings.NewReader(testCase.payload))
+		req.Header.Set("Content-Type", testCase.contentType)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if testCase.classification == "" && recorder.Code != http.StatusOK {
+			t.Errorf("'%s' should have succeeded, but got %d with '%s'", testCase.description, recorder.Code, recorder.Body.String())
+		} else if testCase.classification != "" && !strings.Contains(recorder.Body.String(), testCase.classification) {
+			t.Errorf("'%s' should have failed with a %s, but got %d with '%s'", testCase.description, testCase.classification, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestProfilesOfABouncer(t *testing.T) {
+	b := New(MethodProfile("PUT", "replace"))
+	if _, errs := b.ValidateJson(Listing{}, []byte(`{"id":"1","sku":"A"}`), "PUT"); len(errs) > 0 {
+		t.Errorf("Expected PUT to use the replace profile, but got '%+v'", errs)
+	}
+	if _, errs := ValidateJson(Listing{}, []byte(`{"id":"1","sku":"A"}`), "PUT"); !errs.Has(ImmutableError) {
+		t.Errorf("Expected other Bouncers to keep the create profile for PUT, but got '%+v'", errs)
+	}
+}
+
+func TestCheckProfiles(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	NewBouncerHandler(MisspelledListing{}, okHandler)
+
+	defer func() {
+		err, ok := recover().(*ModelError)
+		if !ok || !strings.Contains(err.Error(), `unknown option "requierd" in the replace tag`) {
+			t.Errorf("Expected the tags of a profile to be checked, but got '%v'", err)
+		}
+	}()
+	NewBouncerHandler(MisspelledListing{}, okHandler, MethodProfile("PUT", "replace"))
+}
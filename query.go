@@ -0,0 +1,153 @@
+package bouncer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// BouncerQueryHandler validates the query string and path parameters of a
+// request against a model, see NewBouncerQueryHandler.
+type BouncerQueryHandler struct {
+	bouncer *Bouncer
+	options options
+	iface   interface{}
+	f       http.Handler
+}
+
+// NewBouncerQueryHandler binds the query string of each request, and the path
+// parameters given by the PathParams option, into a new instance of obj and
+// validates it before calling f. Fields are bound from the query parameter
+// named by their query tag, e.g. `query:"limit"`, falling back to their form
+// or json name, or from a path parameter with `path:"id"`. Values are
+// converted like form values, and a `default:"20"` tag fills in parameters
+// that are missing (with comma separated values for slices).
+//
+// `query:"sort,required"` rejects requests without the parameter, path
+// parameters are always required, and the rules in the validate tag are
+// checked on every parameter that was sent. The bound model can be read with
+// DecodedQuery or Query. Fields tagged with a header name are bound from the
+// request headers, like in NewBouncerHandler.
+func NewBouncerQueryHandler(obj interface{}, f http.Handler, opts ...Option) http.Handler {
+	return DefaultBouncer.QueryHandler(obj, f, opts...)
+}
+
+// QueryHandler is like NewBouncerQueryHandler, but validates with the rules registered on b.
+func (b *Bouncer) QueryHandler(obj interface{}, f http.Handler, opts ...Option) http.Handler {
+	o := b.handlerOptions(opts)
+	b.mustCheck(obj, false, o)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := BouncerQueryHandler{
+			bouncer: b,
+			options: o,
+			f:       f,
+			iface:   obj,
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (h BouncerQueryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query, errs := h.bouncer.validateQuery(h.iface, r, h.options)
+	if len(errs) > 0 {
+		ErrorHandler(errs, w)
+		return
+	}
+
+	h.f.ServeHTTP(w, r.WithContext(withDecodedQuery(r.Context(), query)))
+}
+
+// validateQuery binds the query string and path parameters of req into a new
+// instance of obj and validates it, returning a pointer to the model.
+func (b *Bouncer) validateQuery(obj interface{}, req *http.Request, o options) (interface{}, Errors) {
+	var errors Errors
+	model := reflect.New(modelType(obj))
+
+	var params map[string]string
+	if o.pathParams != nil {
+		params = o.pathParams(req)
+	}
+	fields := bindQuery(&errors, model.Elem(), req.URL.Query(), params)
+	errors = b.bindHeaders(req.Context(), errors, model.Interface(), req.Header)
+
+	errors = b.validateStruct(req.Context(), errors, "query", model.Interface(), fields, fieldPath{})
+	errors = runValidator(errors, model.Interface(), req.Method)
+	return model.Interface(), errors
+}
+
+// bindQuery sets the fields of a struct from query and path parameters and
+// returns the presence of those that were sent, keyed like the presence of a
+// form. Defaults are set on missing fields, but they don't
+// count as sent.
+func bindQuery(errors *Errors, val reflect.Value, query url.Values, params map[string]string) *presence {
+	fields := &presence{keys: map[string]*presence{}}
+	typ := val.Type()
+
+	// embedded structs, such as shared pagination parameters, are promoted
+	for _, f := range jsonFields(typ) {
+		field := f.field
+		if field.Tag.Get("query") == "-" || field.Tag.Get("form") == "-" || field.Tag.Get("header") != "" {
+			continue
+		}
+		target, ok := f.value(val, true)
+		if !ok || !target.CanSet() {
+			continue
+		}
+		name, isPath := queryName(field)
+
+		var values []string
+		if isPath {
+			if value, ok := params[name]; ok {
+				values = []string{value}
+			} else {
+				errors.Add([]string{name}, RequiredError, "Required")
+				continue
+			}
+		} else if queryValues, ok := query[name]; ok {
+			values = queryValues
+		}
+
+		if values == nil {
+			if err := setDefault(target, field); err != nil {
+				panic(fmt.Sprintf("bouncer: invalid default for %s.%s: %s", typ.Name(), field.Name, err))
+			}
+			continue
+		}
+
+		present := &presence{}
+		if err := setFormValues(target, values); err != nil {
+			errors.Add([]string{name}, TypeError, err.Error())
+			present.invalid = true
+		}
+		fields.setField(f, present)
+	}
+	return fields
+}
+
+// setDefault sets target to the value of the default tag of its field, if it
+// has one. Slices take comma separated values.
+func setDefault(target reflect.Value, field reflect.StructField) error {
+	def, ok := field.Tag.Lookup("default")
+	if !ok {
+		return nil
+	}
+	defaults := []string{def}
+	if indirectType(field.Type).Kind() == reflect.Slice {
+		defaults = strings.Split(def, ",")
+	}
+	return setFormValues(target, defaults)
+}
+
+// queryName returns the name of the parameter a field is bound from, and
+// whether it is a path parameter.
+func queryName(field reflect.StructField) (string, bool) {
+	if p := strings.Split(field.Tag.Get("path"), ",")[0]; p != "" {
+		return p, true
+	}
+	if q := strings.Split(field.Tag.Get("query"), ",")[0]; q != "" {
+		return q, false
+	}
+	return formName(field), false
+}
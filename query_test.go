@@ -0,0 +1,108 @@
+package bouncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type (
+	// For exercising query and path parameters
+	ListParams struct {
+		Store  string   `path:"store" validate:"len=4"`
+		Limit  int      `query:"limit" default:"20" validate:"min=1,max=100"`
+		Offset int      `query:"offset" validate:"gte=0"`
+		Sort   string   `query:"sort,required" validate:"oneof=name price"`
+		Tags   []string `query:"tag" default:"new,sale"`
+		Debug  bool     `query:"-"`
+	}
+
+	// For exercising parameters that aren't decoded from json
+	SearchParams struct {
+		Term string `json:"-" query:"q,required"`
+		Page int    `json:"-" query:"page"`
+		// a parameter named like an option, which doesn't make it required
+		Owner string `json:"-" query:"required_by"`
+	}
+)
+
+func storeParams(r *http.Request) map[string]string {
+	if store := strings.TrimPrefix(r.URL.Path, "/stores/"); store != r.URL.Path {
+		return map[string]string{"store": store}
+	}
+	return nil
+}
+
+func TestQueryHandler(t *testing.T) {
+	handler := NewBouncerQueryHandler(ListParams{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if params, ok := Query[ListParams](r.Context()); !ok || params.Limit != 5 {
+			t.Errorf("Expected the bound parameters in the context, but got '%+v'", params)
+		}
+	}), PathParams(storeParams))
+
+	for _, testCase := range []struct {
+		description    string
+		target         string
+		classification string
+	}{
+		{"Valid", "/stores/S001?sort=name&limit=5", ""},
+		{"Missing a required parameter", "/stores/S001?limit=5", RequiredError},
+		{"Missing a path parameter", "/other?sort=name", RequiredError},
+		{"Not a number", "/stores/S001?sort=name&limit=five", TypeError},
+		{"Breaking a rule", "/stores/S001?sort=name&limit=500", MaxError},
+		{"Breaking a rule on a path parameter", "/stores/S1?sort=name", LenError},
+		{"Not one of", "/stores/S001?sort=color", OneOfError},
+	} {
+		req, _ := http.NewRequest("GET", testCase.target, nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if testCase.classification == "" && recorder.Code != http.StatusOK {
+			t.Errorf("'%s' should have been valid, but returned %d with body '%s'", testCase.description, recorder.Code, recorder.Body.String())
+		} else if testCase.classification != "" && !strings.Contains(recorder.Body.String(), testCase.classification) {
+			t.Errorf("'%s' should have returned a %s, but returned %d with body '%s'",
+				testCase.description, testCase.classification, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestQueryDefaults(t *testing.T) {
+	var params ListParams
+	handler := NewQueryHandler(func(w http.ResponseWriter, r *http.Request, query ListParams) {
+		params = query
+	}, PathParams(storeParams))
+
+	req, _ := http.NewRequest("GET", "/stores/S001?sort=price&debug=true", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected the request to be valid, but got %d with body '%s'", recorder.Code, recorder.Body.String())
+	}
+	if params.Store != "S001" || params.Sort != "price" || params.Limit != 20 || params.Offset != 0 || params.Debug {
+		t.Errorf("Expected the parameters to be bound with their defaults, but got '%+v'", params)
+	}
+	if len(params.Tags) != 2 || params.Tags[0] != "new" || params.Tags[1] != "sale" {
+		t.Errorf("Expected the default tags, but got '%+v'", params.Tags)
+	}
+}
+
+func TestQueryIgnoredFields(t *testing.T) {
+	for _, testCase := range []struct {
+		description    string
+		target         string
+		classification string
+	}{
+		{"Valid", "/?q=foo", ""},
+		{"Another parameter doesn't count as sent", "/?page=2", RequiredError},
+	} {
+		req, _ := http.NewRequest("GET", testCase.target, nil)
+		_, errs := DefaultBouncer.validateQuery(SearchParams{}, req, options{})
+		if testCase.classification == "" && len(errs) > 0 {
+			t.Errorf("'%s' should have been valid, but returned '%+v'", testCase.description, errs)
+		} else if testCase.classification != "" && (len(errs) != 1 || !errs.Has(testCase.classification)) {
+			t.Errorf("'%s' should have returned a single %s, but returned '%+v'", testCase.description, testCase.classification, errs)
+		}
+	}
+}
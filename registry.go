@@ -0,0 +1,109 @@
+package bouncer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Bouncer holds the configuration shared by the handlers created from it,
+// such as custom validation rules and decoders. The package level functions
+// use DefaultBouncer.
+type Bouncer struct {
+	mu       sync.RWMutex
+	rules    map[string]rule
+	decoders map[string]bodyDecoder
+	options  options
+
+	// the plans of the struct types validated so far, see Bouncer.plan
+	plans sync.Map
+}
+
+// RuleFunc is a custom validation rule. It receives the request context, the
+// value of the field (with pointers dereferenced) and the parameter given to the
+// rule in the validate tag, e.g. "3" for `validate:"sku=3"`. It returns a non-nil
+// error if the value is invalid.
+//
+// The error is reported with a classification made from the rule name, e.g.
+// "SkuError" for a rule named "sku". Return an Error to choose the
+// classification yourself.
+type RuleFunc func(ctx context.Context, value interface{}, param string) error
+
+// DefaultBouncer is used by NewBouncerHandler, NewBouncerPatchHandler and the
+// other package level functions.
+var DefaultBouncer = New()
+
+// New returns a Bouncer with only the built-in rules and decoders, whose handlers
+// all use the given options.
+func New(opts ...Option) *Bouncer {
+	b := &Bouncer{
+		rules:    map[string]rule{},
+		decoders: map[string]bodyDecoder{},
+		options:  defaultOptions(),
+	}
+	for _, opt := range opts {
+		opt(&b.options)
+	}
+	return b
+}
+
+// RegisterRule adds a rule to DefaultBouncer, see Bouncer.RegisterRule.
+func RegisterRule(name string, fn RuleFunc) {
+	DefaultBouncer.RegisterRule(name, fn)
+}
+
+// RegisterRule makes fn available in the validate tags of models validated by b,
+// under the given name. Registering a rule with the name of a built-in rule
+// replaces it for this Bouncer.
+func (b *Bouncer) RegisterRule(name string, fn RuleFunc) {
+	if name == "" || strings.ContainsAny(name, ",=") {
+		panic("bouncer: invalid rule name " + name)
+	}
+	if fn == nil {
+		panic("bouncer: nil RuleFunc for rule " + name)
+	}
+
+	b.mu.Lock()
+	b.rules[name] = rule{
+		classification: strings.ToUpper(name[:1]) + name[1:] + "Error",
+		custom:         fn,
+	}
+	b.mu.Unlock()
+
+	// plans compiled so far hold the rule this one replaces, if any
+	b.dropPlans()
+}
+
+// lookupRule finds a rule registered on b, falling back to the built-in rules.
+func (b *Bouncer) lookupRule(name string) (rule, bool) {
+	b.mu.RLock()
+	r, ok := b.rules[name]
+	b.mu.RUnlock()
+	if ok {
+		return r, true
+	}
+	r, ok = builtinRules[name]
+	return r, ok
+}
+
+// addRuleError records the error returned by a RuleFunc, if any.
+func addRuleError(errs Errors, name string, classification string, err error) Errors {
+	if err == nil {
+		return errs
+	}
+
+	var e Error
+	if errors.As(err, &e) {
+		if len(e.FieldNames) == 0 {
+			e.FieldNames = []string{name}
+		}
+		if e.Classification == "" {
+			e.Classification = classification
+		}
+		return append(errs, e)
+	}
+
+	errs.Add([]string{name}, classification, err.Error())
+	return errs
+}
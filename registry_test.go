@@ -0,0 +1,78 @@
+package bouncer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type (
+	// For exercising custom rules
+	Product struct {
+		Sku   string `json:"sku" validate:"sku=3"`
+		Color string `json:"color" validate:"palette"`
+	}
+
+	tenantKey struct{}
+)
+
+func newProductBouncer() *Bouncer {
+	b := New()
+	b.RegisterRule("sku", func(ctx context.Context, value interface{}, param string) error {
+		digits, _ := strconv.Atoi(param)
+		if !strings.HasPrefix(value.(string), "SKU-") || len(value.(string)) != 4+digits {
+			return errors.New("Must be a SKU")
+		}
+		return nil
+	})
+	b.RegisterRule("palette", func(ctx context.Context, value interface{}, param string) error {
+		if value.(string) != ctx.Value(tenantKey{}) {
+			return Error{Classification: "PaletteMismatch", Message: "Not in the tenant's palette"}
+		}
+		return nil
+	})
+	return b
+}
+
+func TestRegisterRule(t *testing.T) {
+	b := newProductBouncer()
+
+	_, errs := b.ValidateJson(Product{}, []byte(`{"sku":"SKU-123"}`), "POST")
+	if len(errs) > 0 {
+		t.Errorf("Expected a valid sku, but got '%+v'", errs)
+	}
+
+	_, errs = b.ValidateJson(Product{}, []byte(`{"sku":"123"}`), "POST")
+	if len(errs) != 1 || !errs.Has("SkuError") || errs[0].FieldNames[0] != "sku" {
+		t.Errorf("Expected a single SkuError on sku, but got '%+v'", errs)
+	}
+}
+
+func TestRegisterRuleWithContext(t *testing.T) {
+	handler := newProductBouncer().Handler(Product{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req, _ := http.NewRequest("POST", testRoute, strings.NewReader(`{"color":"red"}`))
+	req.Header.Set("Content-Type", jsonContentType)
+	req = req.WithContext(context.WithValue(req.Context(), tenantKey{}, "blue"))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != StatusUnprocessableEntity || !strings.Contains(recorder.Body.String(), "PaletteMismatch") {
+		t.Errorf("Expected a PaletteMismatch error, but got %d with body '%s'", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestRulesAreScopedToBouncer(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected an unknown rule to panic on a Bouncer it wasn't registered on")
+		}
+	}()
+
+	newProductBouncer()
+	New().ValidateJson(Product{}, []byte(`{"sku":"SKU-123"}`), "POST")
+}
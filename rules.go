@@ -0,0 +1,418 @@
+package bouncer
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// rule checks a single field against the parameter given to it in a validate tag.
+// check returns a human readable message if the value is invalid, or an empty
+// string if it is valid. parent is the struct the field belongs to, which is
+// used by the rules comparing fields with each other.
+// Rules registered with RegisterRule use custom instead of check.
+type rule struct {
+	classification string
+	check          func(field reflect.Value, parent reflect.Value, param string) string
+	custom         RuleFunc
+}
+
+var builtinRules = map[string]rule{
+	"min":      {classification: MinError, check: checkMin},
+	"max":      {classification: MaxError, check: checkMax},
+	"len":      {classification: LenError, check: checkLen},
+	"gt":       {classification: RangeError, check: numericRangeCheck(">", func(c int) bool { return c > 0 })},
+	"gte":      {classification: RangeError, check: numericRangeCheck(">=", func(c int) bool { return c >= 0 })},
+	"lt":       {classification: RangeError, check: numericRangeCheck("<", func(c int) bool { return c < 0 })},
+	"lte":      {classification: RangeError, check: numericRangeCheck("<=", func(c int) bool { return c <= 0 })},
+	"oneof":    {classification: OneOfError, check: checkOneOf},
+	"regex":    {classification: RegexError, check: checkRegex},
+	"email":    {classification: EmailError, check: checkEmail},
+	"url":      {classification: URLError, check: checkURL},
+	"uuid":     {classification: UUIDError, check: checkUUID},
+	"eqfield":  {classification: FieldComparisonError, check: fieldComparisonCheck("equal to", func(c int) bool { return c == 0 })},
+	"nefield":  {classification: FieldComparisonError, check: fieldComparisonCheck("different from", func(c int) bool { return c != 0 })},
+	"gtfield":  {classification: FieldComparisonError, check: fieldComparisonCheck("greater than", func(c int) bool { return c > 0 })},
+	"gtefield": {classification: FieldComparisonError, check: fieldComparisonCheck("greater than or equal to", func(c int) bool { return c >= 0 })},
+	"ltfield":  {classification: FieldComparisonError, check: fieldComparisonCheck("less than", func(c int) bool { return c < 0 })},
+	"ltefield": {classification: FieldComparisonError, check: fieldComparisonCheck("less than or equal to", func(c int) bool { return c <= 0 })},
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// compiled regex parameters, keyed by pattern
+var regexCache sync.Map
+
+// validateRules runs every rule in the field's validate tag against its value.
+// It is only called for fields present in the request; combine the rules with
+// required to reject missing fields.
+// Errors are named by path, the full path to the field, and present is the
+// presence of the field, if known.
+func (b *Bouncer) validateRules(ctx context.Context, errors Errors, path fieldPath, field *fieldPlan, value reflect.Value, parent reflect.Value, present *presence) Errors {
+	if len(field.rules) == 0 {
+		return errors
+	}
+	return b.applyRules(ctx, errors, path, field, field.rules, value, parent, present)
+}
+
+// applyRules runs rules against value. The rules after a dive are run against
+// each element of value instead.
+func (b *Bouncer) applyRules(ctx context.Context, errors Errors, path fieldPath, field *fieldPlan, rules []planRule, value reflect.Value, parent reflect.Value, present *presence) Errors {
+	for i, r := range rules {
+		if r.dive {
+			return b.diveRules(ctx, errors, path, field, rules[i+1:], value, parent, present)
+		}
+		if !r.known {
+			panic(fmt.Sprintf("bouncer: unknown validation rule %q on field %s", r.name, field.field.Name))
+		}
+
+		if r.custom != nil {
+			errors = addRuleError(errors, path.String(), r.classification, r.custom(ctx, dynamic(value).Interface(), r.param))
+		} else if msg := r.check(dynamic(value), parent, r.param); msg != "" {
+			errors.Add([]string{path.String()}, r.classification, msg)
+		}
+	}
+	return errors
+}
+
+// diveRules runs rules against every element of a slice, array or map, naming
+// errors by the index or key of the element. Elements that are null, or
+// couldn't be decoded, are skipped. A value decoded into an interface{} that
+// isn't a list or map is a TypeError.
+func (b *Bouncer) diveRules(ctx context.Context, errors Errors, path fieldPath, field *fieldPlan, rules []planRule, value reflect.Value, parent reflect.Value, present *presence) Errors {
+	value = indirect(value)
+	decoded := value.Kind() == reflect.Interface
+	value = dynamic(value)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			item := present.item(i)
+			elem := unwrapNullable(value.Index(i))
+			if item.isNull() || !item.isValid() || (elem.Kind() == reflect.Ptr && elem.IsNil()) {
+				continue
+			}
+			errors = b.applyRules(ctx, errors, path.index(i), field, rules, elem, parent, item)
+		}
+	case reflect.Map:
+		for _, key := range sortedMapKeys(value) {
+			name := fmt.Sprint(key.Interface())
+			item := present.child(name)
+			elem := unwrapNullable(value.MapIndex(key))
+			if item.isNull() || !item.isValid() || (elem.Kind() == reflect.Ptr && elem.IsNil()) {
+				continue
+			}
+			errors = b.applyRules(ctx, errors, path.key(name), field, rules, elem, parent, item)
+		}
+	case reflect.Invalid:
+	default:
+		if decoded {
+			errors.Add([]string{path.String()}, TypeError, "Expected a list or an object")
+			return errors
+		}
+		panic(fmt.Sprintf("bouncer: dive on field %s, which is not a slice, array or map", field.field.Name))
+	}
+	return errors
+}
+
+// sortedMapKeys returns the keys of a map in order of their string form, so
+// that errors are reported in the same order every time.
+func sortedMapKeys(value reflect.Value) []reflect.Value {
+	keys := value.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
+
+// splitRules splits a validate tag on commas. A comma that is part of a
+// parameter can be escaped with a backslash.
+func splitRules(tag string) []string {
+	var rules []string
+	var current strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			current.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			rules = append(rules, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(tag[i])
+		}
+	}
+	rules = append(rules, current.String())
+
+	// drop empty entries, e.g. from a trailing comma
+	n := 0
+	for _, r := range rules {
+		if r = strings.TrimSpace(r); r != "" {
+			rules[n] = r
+			n++
+		}
+	}
+	return rules[:n]
+}
+
+// isZero reports whether value is the zero value for its type.
+func isZero(value reflect.Value) bool {
+	return !value.IsValid() || value.IsZero()
+}
+
+// dynamic unwraps the values held by interfaces, and the pointers to them,
+// leaving what was decoded into an interface{}.
+func dynamic(value reflect.Value) reflect.Value {
+	value = indirect(value)
+	for value.Kind() == reflect.Interface && !value.IsNil() {
+		value = indirect(value.Elem())
+	}
+	return value
+}
+
+// indirect follows pointers until it reaches a non-pointer value.
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value
+}
+
+// size returns the number to compare against min/max/len: the value itself for
+// numbers, and the length for strings (in runes), slices, arrays and maps.
+func size(value reflect.Value, param string) (float64, float64, bool) {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("bouncer: invalid numeric parameter %q", param))
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), limit, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), limit, true
+	}
+
+	if n, ok := number(value); ok {
+		return n, limit, true
+	}
+	return 0, limit, false
+}
+
+// number returns the value of a numeric kind as a float64.
+func number(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
+
+// sizeUnit describes what min/max/len are measuring for the value.
+func sizeUnit(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
+	}
+	return ""
+}
+
+func checkMin(value reflect.Value, _ reflect.Value, param string) string {
+	if n, limit, ok := size(value, param); ok && n < limit {
+		return fmt.Sprintf("Must be at least %s%s", param, sizeUnit(value))
+	}
+	return ""
+}
+
+func checkMax(value reflect.Value, _ reflect.Value, param string) string {
+	if n, limit, ok := size(value, param); ok && n > limit {
+		return fmt.Sprintf("Must be at most %s%s", param, sizeUnit(value))
+	}
+	return ""
+}
+
+func checkLen(value reflect.Value, _ reflect.Value, param string) string {
+	if n, limit, ok := size(value, param); ok && n != limit {
+		return fmt.Sprintf("Must be exactly %s%s", param, sizeUnit(value))
+	}
+	return ""
+}
+
+// numericRangeCheck builds gt/gte/lt/lte, which only apply to numbers.
+func numericRangeCheck(op string, valid func(int) bool) func(reflect.Value, reflect.Value, string) string {
+	return func(value reflect.Value, _ reflect.Value, param string) string {
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			panic(fmt.Sprintf("bouncer: invalid numeric parameter %q", param))
+		}
+		if n, ok := number(value); ok && !valid(compareFloats(n, limit)) {
+			return fmt.Sprintf("Must be %s %s", op, param)
+		}
+		return ""
+	}
+}
+
+func checkOneOf(value reflect.Value, _ reflect.Value, param string) string {
+	actual := fmt.Sprint(value.Interface())
+	options := strings.Fields(param)
+	for _, option := range options {
+		if actual == option {
+			return ""
+		}
+	}
+	return fmt.Sprintf("Must be one of: %s", strings.Join(options, ", "))
+}
+
+func checkRegex(value reflect.Value, _ reflect.Value, param string) string {
+	if value.Kind() != reflect.String {
+		return ""
+	}
+
+	re, ok := regexCache.Load(param)
+	if !ok {
+		compiled, err := regexp.Compile(param)
+		if err != nil {
+			panic(fmt.Sprintf("bouncer: invalid regex parameter %q: %s", param, err))
+		}
+		re, _ = regexCache.LoadOrStore(param, compiled)
+	}
+
+	if !re.(*regexp.Regexp).MatchString(value.String()) {
+		return fmt.Sprintf("Must match %s", param)
+	}
+	return ""
+}
+
+func checkEmail(value reflect.Value, _ reflect.Value, _ string) string {
+	if value.Kind() != reflect.String {
+		return ""
+	}
+	// reject display names like "Foo <foo@example.com>", only the bare address is accepted
+	addr, err := mail.ParseAddress(value.String())
+	if err != nil || addr.Address != value.String() {
+		return "Must be a valid email address"
+	}
+	return ""
+}
+
+func checkURL(value reflect.Value, _ reflect.Value, _ string) string {
+	if value.Kind() != reflect.String {
+		return ""
+	}
+	u, err := url.Parse(value.String())
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "Must be a valid absolute URL"
+	}
+	return ""
+}
+
+func checkUUID(value reflect.Value, _ reflect.Value, _ string) string {
+	if value.Kind() != reflect.String {
+		return ""
+	}
+	if !uuidPattern.MatchString(value.String()) {
+		return "Must be a valid UUID"
+	}
+	return ""
+}
+
+// fieldComparisonCheck builds the rules comparing a field against another field
+// of the same struct, named by its go field name or json name.
+func fieldComparisonCheck(desc string, valid func(int) bool) func(reflect.Value, reflect.Value, string) string {
+	return func(value reflect.Value, parent reflect.Value, param string) string {
+		other, ok := lookupField(parent, param)
+		if !ok {
+			panic(fmt.Sprintf("bouncer: unknown field %q in field comparison", param))
+		}
+		other = dynamic(other)
+		if isZero(other) {
+			// nothing to compare against, required should catch it if needed
+			return ""
+		}
+
+		// the types of fields are checked by Check, so this only happens to
+		// values decoded into interface{} fields
+		c, ok := compareValues(value, other)
+		if !ok {
+			return fmt.Sprintf("Must be comparable with %s", param)
+		}
+		if !valid(c) {
+			return fmt.Sprintf("Must be %s %s", desc, param)
+		}
+		return ""
+	}
+}
+
+// lookupField finds a field of a struct by its go name or its json name.
+func lookupField(parent reflect.Value, name string) (reflect.Value, bool) {
+	parent = indirect(parent)
+	if parent.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	if f, ok := comparedField(parent.Type(), name); ok {
+		return f.value(parent, false)
+	}
+	return reflect.Value{}, false
+}
+
+// comparedField finds the field of a struct type named by the parameter of a
+// field comparison rule.
+func comparedField(typ reflect.Type, name string) (jsonField, bool) {
+	for _, f := range jsonFields(typ) {
+		if f.field.Name == name || fieldName(f.field) == name {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// compareValues orders two numbers, strings or times, returning -1, 0 or 1.
+func compareValues(a reflect.Value, b reflect.Value) (int, bool) {
+	if a.Type() == timeType && b.Type() == timeType {
+		at, bt := a.Interface().(time.Time), b.Interface().(time.Time)
+		switch {
+		case at.Before(bt):
+			return -1, true
+		case at.After(bt):
+			return 1, true
+		}
+		return 0, true
+	}
+
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+
+	an, aok := number(a)
+	bn, bok := number(b)
+	if aok && bok {
+		return compareFloats(an, bn), true
+	}
+	return 0, false
+}
+
+func compareFloats(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package bouncer
+
+import (
+	"testing"
+	"time"
+)
+
+type (
+	// For exercising the rules in validate tags
+	Account struct {
+		Username string    `json:"username" validate:"min=3,max=12,regex=^[a-z0-9_]+$"`
+		Pin      string    `json:"pin" validate:"len=4"`
+		Age      int       `json:"age" validate:"gte=18,lt=130"`
+		Role     string    `json:"role" validate:"oneof=admin member guest"`
+		Email    string    `json:"email" validate:"email"`
+		Website  string    `json:"website" validate:"url"`
+		Id       string    `json:"id" validate:"uuid"`
+		Tags     []string  `json:"tags" validate:"max=2"`
+		Password string    `json:"password" notrim:"true"`
+		Confirm  string    `json:"confirm" notrim:"true" validate:"eqfield=Password"`
+		Start    time.Time `json:"start"`
+		End      time.Time `json:"end" validate:"gtfield=start"`
+	}
+
+	ruleTestCase struct {
+		description    string
+		payload        string
+		classification string
+	}
+)
+
+var ruleTestCases = []ruleTestCase{
+	{"Valid account", `{"username":"foo_bar","pin":"1234","age":30,"role":"admin","email":"foo@example.com","website":"https://example.com","id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","tags":["a"],"password":"secret","confirm":"secret","start":"2020-01-01T00:00:00Z","end":"2020-01-02T00:00:00Z"}`, ""},
+	{"Empty fields are not checked", `{}`, ""},
+	{"Username too short", `{"username":"fo"}`, MinError},
+	{"Username too long", `{"username":"foo_bar_baz_qux"}`, MaxError},
+	{"Username does not match", `{"username":"Foo Bar"}`, RegexError},
+	{"Pin wrong length", `{"pin":"123"}`, LenError},
+	{"Too young", `{"age":17}`, RangeError},
+	{"Too old", `{"age":130}`, RangeError},
+	{"Unknown role", `{"role":"owner"}`, OneOfError},
+	{"Invalid email", `{"email":"Foo <foo@example.com>"}`, EmailError},
+	{"Relative url", `{"website":"/foo"}`, URLError},
+	{"Invalid uuid", `{"id":"6ba7b810-9dad-11d1-80b4"}`, UUIDError},
+	{"Too many tags", `{"tags":["a","b","c"]}`, MaxError},
+	{"Confirmation mismatch", `{"password":"secret","confirm":"secret "}`, FieldComparisonError},
+	{"End before start", `{"start":"2020-01-02T00:00:00Z","end":"2020-01-01T00:00:00Z"}`, FieldComparisonError},
+}
+
+func TestRules(t *testing.T) {
+	for _, testCase := range ruleTestCases {
+		for _, method := range []string{"POST", "PATCH"} {
+			_, errs := ValidateJson(Account{}, []byte(testCase.payload), method)
+			if testCase.classification == "" && len(errs) > 0 {
+				t.Errorf("'%s' (%s) should have succeeded, but returned errors '%+v'", testCase.description, method, errs)
+			} else if testCase.classification != "" && (len(errs) != 1 || !errs.Has(testCase.classification)) {
+				t.Errorf("'%s' (%s) should have failed with a single %s, but returned '%+v'",
+					testCase.description, method, testCase.classification, errs)
+			}
+		}
+	}
+}
+
+// For exercising field comparisons on values decoded into interface{}
+type Bounds struct {
+	Lo interface{} `json:"lo"`
+	Hi interface{} `json:"hi" validate:"gtfield=lo"`
+}
+
+func TestCompareInterfaces(t *testing.T) {
+	for _, testCase := range []ruleTestCase{
+		{"Ordered", `{"lo":1,"hi":2}`, ""},
+		{"Out of order", `{"lo":"b","hi":"a"}`, FieldComparisonError},
+		{"Different types", `{"lo":1,"hi":"a"}`, FieldComparisonError},
+		{"Not comparable", `{"lo":1,"hi":[2]}`, FieldComparisonError},
+	} {
+		_, errs := ValidateJson(Bounds{}, []byte(testCase.payload), "POST")
+		if testCase.classification == "" && len(errs) > 0 {
+			t.Errorf("'%s' should have succeeded, but returned errors '%+v'", testCase.description, errs)
+		} else if testCase.classification != "" && (len(errs) != 1 || !errs.Has(testCase.classification)) {
+			t.Errorf("'%s' should have failed with a single %s, but returned '%+v'", testCase.description, testCase.classification, errs)
+		}
+	}
+}
+
+func TestSplitRules(t *testing.T) {
+	actual := splitRules(`min=1,regex=^a\,b$,,email`)
+	expected := []string{"min=1", "regex=^a,b$", "email"}
+	if len(actual) != len(expected) {
+		t.Fatalf("Expected %v, but got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("Expected %v, but got %v", expected, actual)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package bouncer
+
+import (
+	"reflect"
+	"sort"
+)
+
+// isStrictModel reports whether a struct opts in to strict decoding with a
+// field tagged `strict:"true"`, usually a blank struct{} field.
+func isStrictModel(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).Tag.Get("strict") == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUnknownFields reports every key of a json document that doesn't decode
+// into a field of typ as an UnknownFieldError, named by its full path, e.g.
+// "address.zip" or "/lines/0/sku". Only structs that are strict, because of
+// the handler's options or their own tag, are checked, along with everything
+// nested in them.
+func checkUnknownFields(errors *Errors, typ reflect.Type, document interface{}, path fieldPath, strict bool) {
+	typ = indirectType(typ)
+	if typ.Kind() == reflect.Struct && typ.Implements(nullableFieldType) {
+		typ = typ.Field(0).Type
+		typ = indirectType(typ)
+	}
+	// types that decode themselves, like time.Time, are taken as they are
+	if reflect.PtrTo(typ).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch v := document.(type) {
+	case map[string]interface{}:
+		switch typ.Kind() {
+		case reflect.Struct:
+			strict = strict || isStrictModel(typ)
+			for _, key := range sortedKeys(v) {
+				field, found := fieldByJsonName(typ, key)
+				if !found {
+					if strict {
+						errors.Add([]string{path.key(key).String()}, UnknownFieldError, "Unknown field")
+					}
+					continue
+				}
+				checkUnknownFields(errors, field.Type, v[key], path.key(key), strict)
+			}
+		case reflect.Map:
+			for _, key := range sortedKeys(v) {
+				checkUnknownFields(errors, typ.Elem(), v[key], path.key(key), strict)
+			}
+		}
+	case []interface{}:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for i, value := range v {
+				checkUnknownFields(errors, typ.Elem(), value, path.index(i), strict)
+			}
+		}
+	}
+}
+
+// sortedKeys returns the keys of a json object in order, so that errors are
+// reported in the same order every time.
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
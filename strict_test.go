@@ -0,0 +1,100 @@
+package bouncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type (
+	// For exercising strict decoding
+	Shipment struct {
+		Reference string         `json:"reference"`
+		Address   Destination    `json:"address"`
+		Parcels   []Parcel       `json:"parcels"`
+		Labels    map[string]Tag `json:"labels"`
+		Audit
+	}
+
+	Destination struct {
+		City string `json:"city"`
+	}
+
+	Parcel struct {
+		_      struct{} `strict:"true"`
+		Weight int      `json:"weight"`
+	}
+
+	Tag struct {
+		Text string `json:"text"`
+	}
+
+	Audit struct {
+		CreatedBy string `json:"created_by"`
+	}
+)
+
+func TestStrict(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, testCase := range []struct {
+		description string
+		handler     http.Handler
+		payload     string
+		status      int
+		unknown     []string
+	}{
+		{"Known fields", NewBouncerHandler(Shipment{}, okHandler, Strict()), `{"reference":"A1","address":{"city":"Oslo"},"parcels":[{"weight":2}],"labels":{"a":{"text":"x"}},"created_by":"me"}`, http.StatusOK, nil},
+		{"Case-insensitive keys", NewBouncerHandler(Shipment{}, okHandler, Strict()), `{"Reference":"A1"}`, http.StatusOK, nil},
+		{"Unknown fields", NewBouncerHandler(Shipment{}, okHandler, Strict()), `{"ref":"A1","address":{"zip":"0150"},"parcels":[{"weight":2},{"height":3}],"labels":{"a":{"colour":"red"}}}`, StatusUnprocessableEntity,
+			[]string{"address.zip", "labels.a.colour", "parcels[1].height", "ref"}},
+		{"Trailing data", NewBouncerHandler(Shipment{}, okHandler, Strict()), `{"reference":"A1"} {"reference":"A2"}`, http.StatusBadRequest, nil},
+		{"Trailing whitespace", NewBouncerHandler(Shipment{}, okHandler, Strict()), "{\"reference\":\"A1\"}\n", http.StatusOK, nil},
+		{"Not strict", NewBouncerHandler(Shipment{}, okHandler), `{"ref":"A1","address":{"zip":"0150"}}`, http.StatusOK, nil},
+		{"Strict model", NewBouncerHandler(Shipment{}, okHandler), `{"ref":"A1","parcels":[{"height":3}]}`, StatusUnprocessableEntity, []string{"parcels[0].height"}},
+		{"Strict patch", NewBouncerPatchHandler(Shipment{}, 1024, okHandler, Strict()), `{"ref":"A1"}`, StatusUnprocessableEntity, []string{"ref"}},
+	} {
+		req, _ := http.NewRequest("POST", testRoute, strings.NewReader(testCase.payload))
+		if strings.HasPrefix(testCase.description, "Strict patch") {
+			req.Method = "PATCH"
+		}
+		req.Header.Set("Content-Type", jsonContentType)
+		recorder := httptest.NewRecorder()
+		testCase.handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != testCase.status {
+			t.Errorf("'%s' should have returned %d, but returned %d with body '%s'", testCase.description, testCase.status, recorder.Code, recorder.Body.String())
+		}
+		if strings.Count(recorder.Body.String(), UnknownFieldError) != len(testCase.unknown) {
+			t.Errorf("'%s' should have returned %d UnknownFieldErrors, but returned '%s'", testCase.description, len(testCase.unknown), recorder.Body.String())
+		}
+		for _, path := range testCase.unknown {
+			if !strings.Contains(recorder.Body.String(), `{"fieldNames":["`+path+`"],"classification":"UnknownFieldError"`) {
+				t.Errorf("'%s' should have reported %s as unknown, but returned '%s'", testCase.description, path, recorder.Body.String())
+			}
+		}
+	}
+}
+
+func TestStrictErrorOrder(t *testing.T) {
+	handler := NewBouncerHandler(Shipment{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), Strict())
+	payload := `{"labels":{"c":{"z":1},"a":{"x":1},"d":{"w":1},"b":{"y":1}}}`
+	expected := []string{"labels.a.x", "labels.b.y", "labels.c.z", "labels.d.w"}
+
+	// the keys of a map would come out in a random order otherwise
+	for i := 0; i < 10; i++ {
+		req, _ := http.NewRequest("POST", testRoute, strings.NewReader(payload))
+		req.Header.Set("Content-Type", jsonContentType)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		last := -1
+		for _, path := range expected {
+			index := strings.Index(recorder.Body.String(), `"`+path+`"`)
+			if index < last {
+				t.Fatalf("Expected the unknown fields in the order %v, but got '%s'", expected, recorder.Body.String())
+			}
+			last = index
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package bouncer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// checkJsonTypes reports every value of a json document that can't be
+// decoded into its field of typ as a TypeError, named by its full path, and
+// marks its presence as invalid so that the field's rules are skipped.
+// Numbers in the document must be json.Numbers, so that they can be checked
+// against the range of their field.
+func checkJsonTypes(errors *Errors, typ reflect.Type, document interface{}, fields *presence, path fieldPath) {
+	if document == nil {
+		return
+	}
+
+	typ = indirectType(typ)
+	if typ.Kind() == reflect.Struct && typ.Implements(nullableFieldType) {
+		typ = indirectType(typ.Field(0).Type)
+	}
+	// types that decode themselves report their own errors
+	if reflect.PtrTo(typ).Implements(jsonUnmarshalerType) {
+		return
+	}
+	if _, ok := document.(string); ok && reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return
+	}
+
+	mismatch := func() {
+		errors.Add([]string{path.String()}, TypeError, fmt.Sprintf("Expected %s, got %s", typ, jsonKind(document)))
+		if fields != nil {
+			fields.invalid = true
+		}
+	}
+
+	switch v := document.(type) {
+	case map[string]interface{}:
+		switch typ.Kind() {
+		case reflect.Struct:
+			for _, key := range sortedKeys(v) {
+				value := v[key]
+				field, found := jsonFieldByName(typ, key)
+				if !found {
+					continue
+				}
+				if _, ok := value.(string); ok && field.quoted {
+					continue
+				}
+				checkJsonTypes(errors, field.field.Type, value, fields.child(key), path.key(key))
+			}
+		case reflect.Map:
+			for _, key := range sortedKeys(v) {
+				value := v[key]
+				if !validMapKey(typ.Key(), key) {
+					errors.Add([]string{path.key(key).String()}, TypeError, fmt.Sprintf("Expected a %s key, got %q", typ.Key(), key))
+					continue
+				}
+				checkJsonTypes(errors, typ.Elem(), value, fields.child(key), path.key(key))
+			}
+		case reflect.Interface:
+		default:
+			mismatch()
+		}
+	case []interface{}:
+		switch typ.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i, value := range v {
+				checkJsonTypes(errors, typ.Elem(), value, fields.item(i), path.index(i))
+			}
+		case reflect.Interface:
+		default:
+			mismatch()
+		}
+	case string:
+		if typ.Kind() != reflect.String && typ.Kind() != reflect.Interface &&
+			!(typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8) {
+			mismatch()
+		}
+	case bool:
+		if typ.Kind() != reflect.Bool && typ.Kind() != reflect.Interface {
+			mismatch()
+		}
+	case json.Number:
+		var err error
+		switch typ.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			_, err = strconv.ParseInt(v.String(), 10, typ.Bits())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			_, err = strconv.ParseUint(v.String(), 10, typ.Bits())
+		case reflect.Float32, reflect.Float64:
+			_, err = strconv.ParseFloat(v.String(), typ.Bits())
+		case reflect.Interface:
+		default:
+			mismatch()
+			return
+		}
+		if err != nil {
+			mismatch()
+		}
+	}
+}
+
+// jsonKind describes a generic json value in a TypeError.
+func jsonKind(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case json.Number:
+		return "number " + v.String()
+	}
+	return "null"
+}
+
+// validMapKey reports whether encoding/json can decode key into a map key of typ.
+func validMapKey(typ reflect.Type, key string) bool {
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err := strconv.ParseInt(key, 10, typ.Bits())
+		return err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		_, err := strconv.ParseUint(key, 10, typ.Bits())
+		return err == nil
+	}
+	return true
+}
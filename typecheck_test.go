@@ -0,0 +1,74 @@
+package bouncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type (
+	// For exercising json type errors
+	Reading struct {
+		Sensor   string            `json:"sensor" create:"required"`
+		Value    float64           `json:"value" validate:"min=0"`
+		Count    int8              `json:"count" validate:"min=1"`
+		Serial   int               `json:"serial,string"`
+		Active   bool              `json:"active"`
+		Location Destination       `json:"location" create:"required"`
+		Samples  []int             `json:"samples"`
+		Limits   map[int]float64   `json:"limits"`
+		Taken    time.Time         `json:"taken"`
+		Note     Nullable[string]  `json:"note"`
+		Extra    map[string]string `json:"extra"`
+	}
+)
+
+func TestJsonTypeErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		payload     string
+		errors      []string
+	}{
+		{"Valid", `{"sensor":"a","value":1.5,"count":3,"serial":"12","active":true,"location":{"city":"Oslo"},"samples":[1,2],"limits":{"1":2.5},"taken":"2024-01-01T00:00:00Z","note":null}`, nil},
+		{"A string for a number", `{"sensor":"a","value":"high","location":{}}`, []string{"value"}},
+		{"Every mismatch at once", `{"sensor":1,"count":"3","active":"yes","location":"Oslo","samples":[1,"two",3.5]}`,
+			[]string{"active", "count", "location", "samples[1]", "samples[2]", "sensor"}},
+		{"Out of range", `{"sensor":"a","count":300,"location":{}}`, []string{"count"}},
+		{"Nested", `{"sensor":"a","location":{"city":5}}`, []string{"location.city"}},
+		{"Map keys and values", `{"sensor":"a","location":{},"limits":{"one":1,"2":"two"},"extra":{"a":1}}`, []string{"extra.a", "limits.2", "limits.one"}},
+		{"Nullable", `{"sensor":"a","location":{},"note":5}`, []string{"note"}},
+	} {
+		req, _ := http.NewRequest("POST", testRoute, strings.NewReader(testCase.payload))
+		req.Header.Set("Content-Type", jsonContentType)
+		recorder := httptest.NewRecorder()
+		NewBouncerHandler(Reading{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(recorder, req)
+
+		body := recorder.Body.String()
+		if testCase.errors == nil {
+			if recorder.Code != http.StatusOK {
+				t.Errorf("'%s' should have been valid, but returned %d with body '%s'", testCase.description, recorder.Code, body)
+			}
+			continue
+		}
+		if recorder.Code != StatusUnprocessableEntity {
+			t.Errorf("'%s' should have returned 422, but returned %d with body '%s'", testCase.description, recorder.Code, body)
+		}
+		if strings.Count(body, `"classification"`) != len(testCase.errors) {
+			t.Errorf("'%s' should have returned only %d TypeErrors, but returned '%s'", testCase.description, len(testCase.errors), body)
+		}
+		for _, path := range testCase.errors {
+			if !strings.Contains(body, `{"fieldNames":["`+path+`"],"classification":"TypeError"`) {
+				t.Errorf("'%s' should have returned a TypeError on %s, but returned '%s'", testCase.description, path, body)
+			}
+		}
+	}
+}
+
+func TestJsonTypeErrorMessage(t *testing.T) {
+	_, errs := ValidateJson(Reading{}, []byte(`{"sensor":"a","value":"high","location":{}}`), "POST")
+	if len(errs) != 1 || errs[0].Message != "Expected float64, got string" {
+		t.Errorf("Expected the message to name the expected and received types, but got '%+v'", errs)
+	}
+}
@@ -0,0 +1,100 @@
+package bouncer
+
+import (
+	"net/http"
+)
+
+// HandlerFunc is a handler that receives the validated request body
+// already decoded into its model type T.
+type HandlerFunc[T any] func(w http.ResponseWriter, r *http.Request, body T)
+
+// PatchHandlerFunc is a handler that receives the validated patch decoded
+// into its model type T, along with the sanitized patch json containing
+// only the keys present in the request. For a JSON Patch body, patch is the
+// zero value and patchJson holds the validated operations.
+type PatchHandlerFunc[T any] func(w http.ResponseWriter, r *http.Request, patch T, patchJson []byte)
+
+// NewHandler is the type-safe counterpart of NewBouncerHandler. The model
+// type is taken from T, so the decoded body is handed straight to f without
+// a context lookup or type assertion. T may be a pointer to the model, in
+// which case f gets the pointer to the decoded body.
+func NewHandler[T any](f HandlerFunc[T], opts ...Option) http.Handler {
+	return HandlerFor(DefaultBouncer, f, opts...)
+}
+
+// HandlerFor is like NewHandler, but validates with the rules registered on b.
+func HandlerFor[T any](b *Bouncer, f HandlerFunc[T], opts ...Option) http.Handler {
+	var model T
+	o := b.handlerOptions(opts)
+	b.mustCheck(model, true, o)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, errs := b.validateRequest(model, r, o)
+		if len(errs) > 0 {
+			ErrorHandler(errs, w)
+			return
+		}
+
+		f(w, r, decodedAs[T](body))
+	})
+}
+
+// NewPatchHandler is the type-safe counterpart of NewBouncerPatchHandler.
+// T may be a pointer to the model, like for NewHandler.
+func NewPatchHandler[T any](maxBodyLength int64, f PatchHandlerFunc[T], opts ...Option) http.Handler {
+	return PatchHandlerFor(DefaultBouncer, maxBodyLength, f, opts...)
+}
+
+// PatchHandlerFor is like NewPatchHandler, but validates with the rules registered on b.
+func PatchHandlerFor[T any](b *Bouncer, maxBodyLength int64, f PatchHandlerFunc[T], opts ...Option) http.Handler {
+	var model T
+	o := b.handlerOptions(opts)
+	b.mustCheck(model, true, o)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := BouncerPatchHandler{
+			bouncer:       b,
+			options:       o,
+			maxBodyLength: maxBodyLength,
+			iface:         model,
+		}
+		r, body, errs := h.validate(w, r)
+		if len(errs) > 0 {
+			ErrorHandler(errs, w)
+			return
+		}
+
+		f(w, r, decodedAs[T](body), PatchBody(r.Context()))
+	})
+}
+
+// NewQueryHandler is the type-safe counterpart of NewBouncerQueryHandler,
+// handing the bound parameters to f as its last argument. T may be a pointer
+// to the model, like for NewHandler.
+func NewQueryHandler[T any](f HandlerFunc[T], opts ...Option) http.Handler {
+	return QueryHandlerFor(DefaultBouncer, f, opts...)
+}
+
+// QueryHandlerFor is like NewQueryHandler, but validates with the rules registered on b.
+func QueryHandlerFor[T any](b *Bouncer, f HandlerFunc[T], opts ...Option) http.Handler {
+	var model T
+	o := b.handlerOptions(opts)
+	b.mustCheck(model, false, o)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, errs := b.validateQuery(model, r, o)
+		if len(errs) > 0 {
+			ErrorHandler(errs, w)
+			return
+		}
+
+		f(w, r.WithContext(withDecodedQuery(r.Context(), query)), decodedAs[T](query))
+	})
+}
+
+// decodedAs converts the pointer returned by the validators into a T,
+// falling back to the zero value when there was no body to decode.
+func decodedAs[T any](body interface{}) T {
+	value, _ := storedAs[T](body)
+	return value
+}
@@ -0,0 +1,61 @@
+package bouncer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewHandler(t *testing.T) {
+	var received Foo
+	called := false
+	handler := NewHandler(func(w http.ResponseWriter, r *http.Request, foo Foo) {
+		called = true
+		received = foo
+	})
+
+	req, _ := http.NewRequest("POST", testRoute, strings.NewReader(`{"title":"  Foo Title  ", "content": "Foo Content"}`))
+	req.Header.Set("Content-Type", jsonContentType)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !called {
+		t.Fatalf("Expected the typed handler to be called")
+	}
+	if received.Title != "Foo Title" || received.Content != "Foo Content" {
+		t.Errorf("Expected the decoded and trimmed Foo, but got %+v", received)
+	}
+
+	req, _ = http.NewRequest("POST", testRoute, strings.NewReader(`{"content": "Foo Content"}`))
+	req.Header.Set("Content-Type", jsonContentType)
+	called = false
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if called {
+		t.Errorf("Expected the typed handler NOT to be called for an invalid body")
+	}
+	if recorder.Code != StatusUnprocessableEntity {
+		t.Errorf("Expected HTTP status %d, but got %d", StatusUnprocessableEntity, recorder.Code)
+	}
+}
+
+func TestNewPatchHandler(t *testing.T) {
+	var received Foo
+	var receivedJson string
+	handler := NewPatchHandler(1024, func(w http.ResponseWriter, r *http.Request, foo Foo, patchJson []byte) {
+		received = foo
+		receivedJson = string(patchJson)
+	})
+
+	req, _ := http.NewRequest("PATCH", testRoute, strings.NewReader(`{"content": "  New Content  "}`))
+	req.Header.Set("Content-Type", jsonContentType)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if received.Content != "New Content" {
+		t.Errorf("Expected the decoded and trimmed Foo, but got %+v", received)
+	}
+	if receivedJson != `{"content":"New Content"}` {
+		t.Errorf("Expected the sanitized patch json, but got '%s'", receivedJson)
+	}
+}
@@ -0,0 +1,27 @@
+package bouncer
+
+// Validator can be implemented by a model to check rules spanning several
+// fields, such as an end date after a start date. Validate is called with the
+// request method after the tag based validation has run, and the errors it
+// returns are reported along with those from the tags. An Error may name
+// several fields in FieldNames.
+//
+// Only the top level model is checked for Validator, and not when the body,
+// or a value in it, could not be decoded.
+type Validator interface {
+	Validate(method string) Errors
+}
+
+// runValidator merges the errors from the model's Validator, if it has one.
+// obj is a pointer to the model, so both value and pointer receivers are found.
+// Cross-field rules only make sense on a fully decoded model, so it is
+// skipped after a DeserializationError or a TypeError.
+func runValidator(errors Errors, obj interface{}, method string) Errors {
+	if errors.Has(DeserializationError) || errors.Has(TypeError) {
+		return errors
+	}
+	if v, ok := obj.(Validator); ok {
+		errors = append(errors, v.Validate(method)...)
+	}
+	return errors
+}
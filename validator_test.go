@@ -0,0 +1,55 @@
+package bouncer
+
+import (
+	"testing"
+	"time"
+)
+
+// For exercising the Validator interface
+type Booking struct {
+	Email string    `json:"email"`
+	Phone string    `json:"phone"`
+	Start time.Time `json:"start" create:"required"`
+	End   time.Time `json:"end" create:"required"`
+}
+
+func (b *Booking) Validate(method string) Errors {
+	var errs Errors
+	if method == "POST" && b.Email == "" && b.Phone == "" {
+		errs.Add([]string{"email", "phone"}, RequiredError, "Either email or phone is required")
+	}
+	if b.End.Before(b.Start) {
+		errs.Add([]string{"start", "end"}, "DateRangeError", "End must be after start")
+	}
+	return errs
+}
+
+func TestValidator(t *testing.T) {
+	_, errs := ValidateJson(Booking{}, []byte(`{"email":"foo@example.com","start":"2020-01-01T00:00:00Z","end":"2020-01-02T00:00:00Z"}`), "POST")
+	if len(errs) > 0 {
+		t.Errorf("Expected a valid booking, but got '%+v'", errs)
+	}
+
+	_, errs = ValidateJson(Booking{}, []byte(`{"start":"2020-01-02T00:00:00Z","end":"2020-01-01T00:00:00Z"}`), "POST")
+	if len(errs) != 2 || !errs.Has(RequiredError) || !errs.Has("DateRangeError") {
+		t.Fatalf("Expected a RequiredError and a DateRangeError, but got '%+v'", errs)
+	}
+	if len(errs[0].FieldNames) != 2 {
+		t.Errorf("Expected the error to span two fields, but got %v", errs[0].FieldNames)
+	}
+
+	_, errs = ValidateJson(Booking{}, []byte(`{"start":"2020-01-02T00:00:00Z","end":"2020-01-01T00:00:00Z"}`), "PATCH")
+	if len(errs) != 1 || !errs.Has("DateRangeError") {
+		t.Errorf("Expected only a DateRangeError on PATCH, but got '%+v'", errs)
+	}
+
+	_, errs = ValidateJson(Booking{}, []byte(`{"start":`), "POST")
+	if errs.Has("DateRangeError") {
+		t.Errorf("Expected the Validator to be skipped for an undecodable body, but got '%+v'", errs)
+	}
+
+	_, errs = ValidateJson(Booking{}, []byte(`{"email":"foo@example.com","start":"2020-01-02T00:00:00Z","end":5}`), "POST")
+	if len(errs) != 1 || !errs.Has(TypeError) {
+		t.Errorf("Expected the Validator to be skipped for a value of the wrong type, but got '%+v'", errs)
+	}
+}